@@ -2,9 +2,12 @@ package quote
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	db "quote/db"
+	mail "quote/mail"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +16,11 @@ import (
 )
 
 var database *db.Database
+var mailConfig mail.Config
+
+// deletePolicy decides whether entries which other entries depend on are
+// deleted with them or not at all (see db.ParseDeletePolicy)
+var deletePolicy string
 var helpMessage string
 
 func help(w http.ResponseWriter, r *http.Request) {
@@ -34,6 +42,35 @@ func filterBooks(books []db.Book, filters ...string) (res []db.Book) {
 	return
 }
 
+// cascader is an entry which other entries depend on, so that its deletion
+// is subject to the delete policy
+type cascader interface {
+	Delete() error
+	DeleteCascade() error
+}
+
+// deleteEntry deletes `entry` with the `id` according to the delete policy,
+// a deletion refused by the policy is answered with 409 Conflict
+func deleteEntry(w http.ResponseWriter, entry cascader, id int) {
+	var err error
+	if deletePolicy == db.CascadePolicy {
+		err = entry.DeleteCascade()
+	} else {
+		err = entry.Delete()
+	}
+	if errors.Is(err, db.ErrDependents) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+		return
+	}
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
 func filterQuotes(quotes []db.Quote, filters ...string) (res []db.Quote) {
 	for _, quote := range quotes {
 		if quote.Filter(filters...) {
@@ -179,6 +216,30 @@ func postTopic(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
 }
 
+// deleteTopic deletes the topic `id`, books and subscriptions depend on it
+func deleteTopic(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	topic, err := database.GetTopic(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if topic == db.DefaultTopic {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	deleteEntry(w, topic, id)
+}
+
 func patchTopic(w http.ResponseWriter, r *http.Request) {
 	id := r.PostFormValue("Id")
 	topicId, err := strconv.Atoi(id)
@@ -346,6 +407,31 @@ func postAuthor(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
 }
 
+// deleteAuthor deletes the author `id`, books and subscriptions depend on
+// them
+func deleteAuthor(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	author, err := database.GetAuthor(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if author == db.DefaultAuthor {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	deleteEntry(w, author, id)
+}
+
 func patchAuthor(w http.ResponseWriter, r *http.Request) {
 	id := r.PostFormValue("Id")
 	authorId, err := strconv.Atoi(id)
@@ -513,6 +599,31 @@ func postLanguage(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
 }
 
+// deleteLanguage deletes the language `id`, books and subscriptions depend
+// on it
+func deleteLanguage(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	language, err := database.GetLanguage(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if language == db.DefaultLanguage {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	deleteEntry(w, language, id)
+}
+
 func patchLanguage(w http.ResponseWriter, r *http.Request) {
 	id := r.PostFormValue("Id")
 	languageId, err := strconv.Atoi(id)
@@ -684,20 +795,25 @@ func postBook(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
-	book := database.NewBook(author, topic, language)
-	book.Title = r.PostFormValue("Title")
-	book.ISBN.Scan(r.PostFormValue("ISBN"))
+	bookReleaseDate := time.Now()
 	releaseDate := r.PostFormValue("ReleaseDate")
 	if releaseDate != "" {
-		book.ReleaseDate, err = time.Parse(time.ANSIC, releaseDate)
+		bookReleaseDate, err = time.Parse(time.ANSIC, releaseDate)
 		if err != nil {
 			fail(w, err)
 			return
 		}
-	} else {
-		book.ReleaseDate = time.Now()
 	}
-	bookId, err := book.Commit()
+	// the author, topic and language are committed together with the book
+	var bookId int
+	err = database.WithTx(func(tx *db.Tx) (err error) {
+		book := tx.NewBook(author, topic, language)
+		book.Title = r.PostFormValue("Title")
+		book.ISBN.Scan(r.PostFormValue("ISBN"))
+		book.ReleaseDate = bookReleaseDate
+		bookId, err = book.Commit()
+		return
+	})
 	if err != nil {
 		fail(w, err)
 		return
@@ -706,6 +822,30 @@ func postBook(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, bookId)))
 }
 
+// deleteBook deletes the book `id`, quotes and subscriptions depend on it
+func deleteBook(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	book, err := database.GetBook(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if book == db.DefaultBook {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	deleteEntry(w, book, id)
+}
+
 func patchBook(w http.ResponseWriter, r *http.Request) {
 	id := r.PostFormValue("Id")
 	bookId, err := strconv.Atoi(id)
@@ -815,6 +955,75 @@ func getQuote(w http.ResponseWriter, r *http.Request) {
 	w.Write(response)
 }
 
+// getQuoteCard returns the quote card of the quote `id` as PNG image
+func getQuoteCard(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	quote, err := database.GetQuote(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if quote == db.DefaultQuote {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	card, err := mailConfig.Card(quote)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.Header().Set("Content-type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	w.Write(card)
+}
+
+// getQuotesOnThisDay returns quotes recorded on the calendar day of the
+// optional `date` (default: today) in earlier years, or close to it
+func getQuotesOnThisDay(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	selector := mail.OnThisDaySelector{}
+	if val := query.Get("date"); val != "" {
+		date, err := time.ParseInLocation("2006-01-02", val, time.Local)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+			return
+		}
+		selector.Date = date
+	}
+	count := 5
+	if val := query.Get("count"); val != "" {
+		var err error
+		count, err = strconv.Atoi(val)
+		if err != nil || count <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf(`{"error": "invalid count %q"}`, val)))
+			return
+		}
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	response, err := json.Marshal(selector.Select(quotes, count))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
 func postQuote(w http.ResponseWriter, r *http.Request) {
 	var err error
 	id := r.PostFormValue("BookId")
@@ -832,20 +1041,25 @@ func postQuote(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
-	quote := database.NewQuote(book)
-	quote.Quote = r.PostFormValue("Quote")
-	quote.RecordDate = time.Now()
+	quotePage := 0
 	page := r.PostFormValue("Page")
 	if page != "" {
-		quote.Page, err = strconv.Atoi(page)
+		quotePage, err = strconv.Atoi(page)
 		if err != nil {
 			fail(w, err)
 			return
 		}
-	} else {
-		quote.Page = 0
 	}
-	quoteId, err := quote.Commit()
+	// the book is committed together with the quote
+	var quoteId int
+	err = database.WithTx(func(tx *db.Tx) (err error) {
+		quote := tx.NewQuote(book)
+		quote.Quote = r.PostFormValue("Quote")
+		quote.RecordDate = time.Now()
+		quote.Page = quotePage
+		quoteId, err = quote.Commit()
+		return
+	})
 	if err != nil {
 		fail(w, err)
 		return
@@ -854,6 +1068,37 @@ func postQuote(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, quoteId)))
 }
 
+// deleteQuote deletes the quote `id` with its deliveries, review and
+// feedback
+func deleteQuote(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	quote, err := database.GetQuote(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if quote == db.DefaultQuote {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = quote.Delete()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
 func patchQuote(w http.ResponseWriter, r *http.Request) {
 	id := r.PostFormValue("Id")
 	quoteId, err := strconv.Atoi(id)
@@ -889,39 +1134,698 @@ func patchQuote(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, quoteId)))
 }
 
-func jsonContentWrapper(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-type", "application/json")
-		h.ServeHTTP(w, r)
-	})
+func getReview(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	review, err := database.GetReview(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if review == db.DefaultReview {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(review)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
 }
 
-// HTTP Methods
-const (
-	Get    = "GET"    // -> database select
-	Post   = "POST"   // -> database insert
-	Patch  = "PATCH"  // -> database patch
-	Delete = "DELETE" // -> database drop
-)
-
-func GetRouter(db *db.Database) (router *mux.Router) {
-	database = db
-	router = mux.NewRouter()
-
-	root := router.PathPrefix("/api").Subrouter()
-	root.Use(jsonContentWrapper)
-	root.Path("").HandlerFunc(help)
+// deleteReview deletes the review of the quote `id`, which resets its
+// spaced repetition
+func deleteReview(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	review, err := database.GetReview(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if review == db.DefaultReview {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = review.Delete()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
 
-	topicsRouter := root.PathPrefix("/topics").Subrouter()
-	// Get Methods
-	topicsRouter.
-		Path("").
-		Queries("q", "{search}").
-		HandlerFunc(searchTopics).
-		Methods(Get)
-	topicsRouter.
-		Path("").
-		HandlerFunc(getTopics).
+func postReview(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	quote, err := database.GetQuote(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if quote == db.DefaultQuote {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	grade, err := strconv.Atoi(r.PostFormValue("Grade"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	review, err := database.GetReview(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if review == db.DefaultReview {
+		review = database.NewReview(id)
+	}
+	review, err = review.Grade(grade, time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+		return
+	}
+	_, err = review.Commit()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
+func getDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := database.GetDeliveries()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	response, err := json.Marshal(deliveries)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func searchDeliveries(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	var deliveries []db.Delivery
+	if val, ok := pathParams["search"]; ok {
+		search := strings.Split(val, " ")
+		for _, q := range search {
+			searchResult, err := database.SearchDeliveries(q)
+			if err != nil {
+				fail(w, err)
+				return
+			}
+			for _, delivery := range searchResult {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+	}
+	response, err := json.Marshal(deliveries)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// deleteDelivery deletes the delivery `id` from the delivery history
+func deleteDelivery(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	delivery, err := database.GetDelivery(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if delivery == db.DefaultDelivery {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = delivery.Delete()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
+// readSubscription sets the fields of `subscription` which are present in the
+// form of `r` and validates its schedule and strategy
+func readSubscription(r *http.Request, subscription *db.Subscription) (err error) {
+	if recipient := r.PostFormValue("Recipient"); recipient != "" {
+		subscription.Recipient = recipient
+	}
+	if schedule := r.PostFormValue("Schedule"); schedule != "" {
+		subscription.Schedule = schedule
+	}
+	if strategy := r.PostFormValue("Strategy"); strategy != "" {
+		subscription.Strategy = strategy
+	}
+	ids := map[string]*int{
+		"Count":      &subscription.Count,
+		"TopicId":    &subscription.TopicId,
+		"AuthorId":   &subscription.AuthorId,
+		"LanguageId": &subscription.LanguageId,
+		"BookId":     &subscription.BookId,
+	}
+	for key, id := range ids {
+		if val := r.PostFormValue(key); val != "" {
+			*id, err = strconv.Atoi(val)
+			if err != nil {
+				return
+			}
+		}
+	}
+	// these settings can be reset by passing an empty value
+	settings := map[string]*string{
+		"TimeZone":   &subscription.TimeZone,
+		"SendTime":   &subscription.SendTime,
+		"QuietStart": &subscription.QuietStart,
+		"QuietEnd":   &subscription.QuietEnd,
+		"BookQueue":  &subscription.BookQueue,
+	}
+	for key, setting := range settings {
+		if _, ok := r.PostForm[key]; ok {
+			*setting = r.PostFormValue(key)
+		}
+	}
+	if quiz := r.PostFormValue("Quiz"); quiz != "" {
+		subscription.Quiz, err = strconv.ParseBool(quiz)
+		if err != nil {
+			return
+		}
+	}
+	if subscription.Recipient == "" {
+		return fmt.Errorf("missing Recipient")
+	}
+	if _, err = subscription.Queue(); err != nil {
+		return
+	}
+	if _, err = mail.ScheduleOf(*subscription); err != nil {
+		return
+	}
+	_, err = mail.NewSelector(subscription.Strategy, subscription.TopicId, subscription.BookId)
+	return
+}
+
+func getSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	response, err := json.Marshal(subscriptions)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func searchSubscriptions(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	var subscriptions []db.Subscription
+	if val, ok := pathParams["search"]; ok {
+		search := strings.Split(val, " ")
+		for _, q := range search {
+			searchResult, err := database.SearchSubscriptions(q)
+			if err != nil {
+				fail(w, err)
+				return
+			}
+			for _, subscription := range searchResult {
+				subscriptions = append(subscriptions, subscription)
+			}
+		}
+	}
+	response, err := json.Marshal(subscriptions)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func getSubscription(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	subscription, err := database.GetSubscription(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if subscription == db.DefaultSubscription {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(subscription)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func postSubscription(w http.ResponseWriter, r *http.Request) {
+	subscription := database.NewSubscription(r.PostFormValue("Recipient"))
+	err := readSubscription(r, &subscription)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+		return
+	}
+	id, err := subscription.Commit()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
+func patchSubscription(w http.ResponseWriter, r *http.Request) {
+	id := r.PostFormValue("Id")
+	subscriptionId, err := strconv.Atoi(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	subscription, err := database.GetSubscription(subscriptionId)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if db.DefaultSubscription == subscription {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = readSubscription(r, &subscription)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+		return
+	}
+	_, err = subscription.Commit()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, subscriptionId)))
+}
+
+func deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	subscription, err := database.GetSubscription(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if subscription == db.DefaultSubscription {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = subscription.Delete()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
+func getMessages(w http.ResponseWriter, r *http.Request) {
+	messages, err := database.GetMessages()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	response, err := json.Marshal(messages)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func getMessagesOfStatus(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	messages, err := database.MessagesOfStatus(pathParams["status"])
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	response, err := json.Marshal(messages)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func getMessage(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	message, err := database.GetMessage(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if message == db.DefaultMessage {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	response, err := json.Marshal(message)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// retryMessage resets a message of the outbox, such that its delivery is
+// attempted again with the next run of the outbox
+func retryMessage(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	message, err := database.GetMessage(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if message == db.DefaultMessage {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if message.Status == db.MessageSent {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": "message has already been sent"}`))
+		return
+	}
+	message.Status = db.MessagePending
+	message.Attempts = 0
+	message.NextAttempt = time.Now()
+	_, err = message.Commit()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
+// deleteMessage deletes the message `id` from the outbox
+func deleteMessage(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	id := -1
+	var err error
+	if val, ok := pathParams["id"]; ok {
+		id, err = strconv.Atoi(val)
+		if err != nil {
+			fail(w, err)
+			return
+		}
+	}
+	message, err := database.GetMessage(id)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if message == db.DefaultMessage {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	err = message.Delete()
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Id": %d}`, id)))
+}
+
+// previewReminder renders the next reminder of the mail configuration without
+// sending it
+func previewReminder(w http.ResponseWriter, r *http.Request) {
+	preview, err := mailConfig.Preview(database)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	response, err := json.Marshal(preview)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// sendReminder sends a reminder of the mail configuration immediately
+func sendReminder(w http.ResponseWriter, r *http.Request) {
+	messages, err := mailConfig.Send(database)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	if len(messages) == 0 {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": "there are no quotes to send"}`))
+		return
+	}
+	response, err := json.Marshal(messages)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// feedbackLink is the signed feedback link of a request
+type feedbackLink struct {
+	Action    string
+	Recipient string
+	QuoteId   int
+	Issued    string
+	Token     string
+}
+
+// readFeedbackLink reads the feedback link of the request and verifies its
+// token, writing the error response if it is not valid
+func readFeedbackLink(w http.ResponseWriter, r *http.Request) (link feedbackLink, ok bool) {
+	query := r.URL.Query()
+	link = feedbackLink{
+		Action:    mux.Vars(r)["action"],
+		Recipient: query.Get("recipient"),
+		Issued:    query.Get("issued"),
+		Token:     query.Get("token"),
+	}
+	if val := query.Get("quote"); val != "" {
+		var err error
+		link.QuoteId, err = strconv.Atoi(val)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+			return
+		}
+	}
+	err := mailConfig.VerifyFeedback(link.Action, link.Recipient, link.QuoteId,
+		link.Issued, link.Token)
+	return link, feedbackError(w, err)
+}
+
+// feedbackError writes the response of the `err` of a feedback link,
+// returning whether there was no error
+func feedbackError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case nil:
+		return true
+	case mail.ErrUnknownAction:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+	case mail.ErrInvalidToken, mail.ErrExpiredToken:
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(fmt.Sprintf(`{"error": "%s"}`, err)))
+	default:
+		fail(w, err)
+	}
+	return false
+}
+
+// feedbackQuestions are the questions of the confirmation page of each
+// feedback action
+var feedbackQuestions = map[string]string{
+	mail.FavoriteAction:    "Show this quote more often?",
+	mail.LessAction:        "Show this quote less often?",
+	mail.SnoozeAction:      "Pause this quote for 30 days?",
+	mail.UnsubscribeAction: "Unsubscribe from all reminders?",
+}
+
+// confirmationPage asks to confirm a feedback link, the form posts it back
+var confirmationPage = template.Must(template.New("confirmation").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Quote-reminder</title></head>
+<body>
+<form method="post" action="{{.Url}}">
+<p>{{.Question}}</p>
+<p>{{.Recipient}}</p>
+<button type="submit">Confirm</button>
+</form>
+</body>
+</html>
+`))
+
+// confirmFeedback shows a page to confirm the action of a signed feedback
+// link. The link is opened with GET, which must not change anything, as mail
+// scanners and link prefetchers open it as well.
+func confirmFeedback(w http.ResponseWriter, r *http.Request) {
+	link, ok := readFeedbackLink(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	confirmationPage.Execute(w, struct {
+		Url       string
+		Question  string
+		Recipient string
+	}{r.URL.RequestURI(), feedbackQuestions[link.Action], link.Recipient})
+}
+
+// feedback applies the action of a signed feedback link of a reminder, which
+// is authorized by its token instead of an api login
+func feedback(w http.ResponseWriter, r *http.Request) {
+	link, ok := readFeedbackLink(w, r)
+	if !ok {
+		return
+	}
+	err := mailConfig.Feedback(database, link.Action, link.Recipient, link.QuoteId,
+		link.Issued, link.Token)
+	if !feedbackError(w, err) {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(fmt.Sprintf(`{"Action": "%s"}`, link.Action)))
+}
+
+func jsonContentWrapper(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-type", "application/json")
+		h.ServeHTTP(w, r)
+	})
+}
+
+// HTTP Methods
+const (
+	Get    = "GET"    // -> database select
+	Post   = "POST"   // -> database insert
+	Patch  = "PATCH"  // -> database patch
+	Delete = "DELETE" // -> database drop
+)
+
+func GetRouter(db *db.Database, config mail.Config, policy string) (router *mux.Router) {
+	database = db
+	mailConfig = config
+	deletePolicy = policy
+	router = mux.NewRouter()
+
+	root := router.PathPrefix("/api").Subrouter()
+	root.Use(jsonContentWrapper)
+	root.Path("").HandlerFunc(help)
+
+	topicsRouter := root.PathPrefix("/topics").Subrouter()
+	// Get Methods
+	topicsRouter.
+		Path("").
+		Queries("q", "{search}").
+		HandlerFunc(searchTopics).
+		Methods(Get)
+	topicsRouter.
+		Path("").
+		HandlerFunc(getTopics).
 		Methods(Get)
 	topicsRouter.
 		Path("/{id:[0-9]+}").
@@ -955,6 +1859,11 @@ func GetRouter(db *db.Database) (router *mux.Router) {
 		Path("").
 		HandlerFunc(patchTopic).
 		Methods(Patch)
+	// Delete Methods
+	topicsRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteTopic).
+		Methods(Delete)
 
 	authorsRouter := root.PathPrefix("/authors").Subrouter()
 	// Get Methods
@@ -999,6 +1908,11 @@ func GetRouter(db *db.Database) (router *mux.Router) {
 		Path("").
 		HandlerFunc(patchAuthor).
 		Methods(Patch)
+	// Delete Methods
+	authorsRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteAuthor).
+		Methods(Delete)
 
 	languagesRouter := root.PathPrefix("/languages").Subrouter()
 	// Get Methods
@@ -1035,6 +1949,11 @@ func GetRouter(db *db.Database) (router *mux.Router) {
 		Path("").
 		HandlerFunc(patchLanguage).
 		Methods(Patch)
+	// Delete Methods
+	languagesRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteLanguage).
+		Methods(Delete)
 
 	booksRouter := root.PathPrefix("/books").Subrouter()
 	// Get Methods
@@ -1070,6 +1989,11 @@ func GetRouter(db *db.Database) (router *mux.Router) {
 		Path("").
 		HandlerFunc(patchBook).
 		Methods(Patch)
+	// Delete Methods
+	booksRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteBook).
+		Methods(Delete)
 
 	quotesRouter := root.PathPrefix("/quotes").Subrouter()
 	// Get Methods
@@ -1086,16 +2010,139 @@ func GetRouter(db *db.Database) (router *mux.Router) {
 		Path("/{id:[0-9]+}").
 		HandlerFunc(getQuote).
 		Methods(Get)
+	quotesRouter.
+		Path("/{id:[0-9]+}/review").
+		HandlerFunc(getReview).
+		Methods(Get)
+	quotesRouter.
+		Path("/on-this-day").
+		HandlerFunc(getQuotesOnThisDay).
+		Methods(Get)
+	quotesRouter.
+		Path("/{id:[0-9]+}/card.png").
+		HandlerFunc(getQuoteCard).
+		Methods(Get)
 	// Post Methods
 	quotesRouter.
 		Path("").
 		HandlerFunc(postQuote).
 		Methods(Post)
+	quotesRouter.
+		Path("/{id:[0-9]+}/review").
+		HandlerFunc(postReview).
+		Methods(Post)
 	// Patch Methods
 	quotesRouter.
 		Path("").
 		HandlerFunc(patchQuote).
 		Methods(Patch)
+	// Delete Methods
+	quotesRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteQuote).
+		Methods(Delete)
+	quotesRouter.
+		Path("/{id:[0-9]+}/review").
+		HandlerFunc(deleteReview).
+		Methods(Delete)
+
+	deliveriesRouter := root.PathPrefix("/deliveries").Subrouter()
+	// Get Methods
+	deliveriesRouter.
+		Path("").
+		Queries("q", "{search}").
+		HandlerFunc(searchDeliveries).
+		Methods(Get)
+	deliveriesRouter.
+		Path("").
+		HandlerFunc(getDeliveries).
+		Methods(Get)
+	// Delete Methods
+	deliveriesRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteDelivery).
+		Methods(Delete)
+
+	subscriptionsRouter := root.PathPrefix("/subscriptions").Subrouter()
+	// Get Methods
+	subscriptionsRouter.
+		Path("").
+		Queries("q", "{search}").
+		HandlerFunc(searchSubscriptions).
+		Methods(Get)
+	subscriptionsRouter.
+		Path("").
+		HandlerFunc(getSubscriptions).
+		Methods(Get)
+	subscriptionsRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(getSubscription).
+		Methods(Get)
+	// Post Methods
+	subscriptionsRouter.
+		Path("").
+		HandlerFunc(postSubscription).
+		Methods(Post)
+	// Patch Methods
+	subscriptionsRouter.
+		Path("").
+		HandlerFunc(patchSubscription).
+		Methods(Patch)
+	// Delete Methods
+	subscriptionsRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteSubscription).
+		Methods(Delete)
+
+	outboxRouter := root.PathPrefix("/outbox").Subrouter()
+	// Get Methods
+	outboxRouter.
+		Path("").
+		Queries("status", "{status}").
+		HandlerFunc(getMessagesOfStatus).
+		Methods(Get)
+	outboxRouter.
+		Path("").
+		HandlerFunc(getMessages).
+		Methods(Get)
+	outboxRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(getMessage).
+		Methods(Get)
+	// Post Methods
+	outboxRouter.
+		Path("/{id:[0-9]+}/retry").
+		HandlerFunc(retryMessage).
+		Methods(Post)
+	// Delete Methods
+	outboxRouter.
+		Path("/{id:[0-9]+}").
+		HandlerFunc(deleteMessage).
+		Methods(Delete)
+
+	remindersRouter := root.PathPrefix("/reminders").Subrouter()
+	// Get Methods
+	remindersRouter.
+		Path("/preview").
+		HandlerFunc(previewReminder).
+		Methods(Get)
+	// Post Methods
+	remindersRouter.
+		Path("/send").
+		HandlerFunc(sendReminder).
+		Methods(Post)
+
+	feedbackRouter := root.PathPrefix("/feedback").Subrouter()
+	// Get Methods
+	feedbackRouter.
+		Path("/{action}").
+		HandlerFunc(confirmFeedback).
+		Methods(Get)
+	// Post Methods (including the one-click unsubscribe of RFC 8058)
+	feedbackRouter.
+		Path("/{action}").
+		HandlerFunc(feedback).
+		Methods(Post)
 
 	// Create help message by walking the available routes
 	helpMessage = fmt.Sprintf("Following routes are available:\n")
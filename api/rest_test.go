@@ -3,12 +3,15 @@ package quote
 import (
 	"encoding/json"
 	"fmt"
+	"image/png"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	db "quote/db"
+	mail "quote/mail"
+	secret "quote/secret"
 	"strings"
 	"testing"
 
@@ -2177,3 +2180,1235 @@ func TestPatchKnownQuote(t *testing.T) {
 		t.Errorf(bodyError, expectedBody, actualBody)
 	}
 }
+
+func TestGetDeliveries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, recipient := range []string{"first@mail.com", "second@mail.com"} {
+		_, err = database.NewDelivery(1, recipient).Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(getDeliveries)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedDeliveries, err := database.GetDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal(expectedDeliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestSearchDeliveries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/?q=second", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, recipient := range []string{"first@mail.com", "second@mail.com"} {
+		_, err = database.NewDelivery(1, recipient).Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/", searchDeliveries).Queries("q", "{search}")
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedDeliveries, err := database.SearchDeliveries("second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expectedDeliveries) != 1 {
+		t.Fatalf("Expected exactly one delivery but got %d", len(expectedDeliveries))
+	}
+	expectedJson, err := json.Marshal(expectedDeliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestPostReviewOfUnknownQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	data := url.Values{}
+	data.Add("Grade", "4")
+	req, err := http.NewRequest(Post, "/69/review", strings.NewReader(data.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/review", postReview).Methods(Post)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestPostReviewInvalidGrade(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	data := url.Values{}
+	data.Add("Grade", "6")
+	req, err := http.NewRequest(Post, "/1/review", strings.NewReader(data.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/review", postReview).Methods(Post)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusBadRequest
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestPostReviewOfKnownQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	data := url.Values{}
+	data.Add("Grade", "4")
+	req, err := http.NewRequest(Post, "/1/review", strings.NewReader(data.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/review", postReview).Methods(Post)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := `{"Id": 1}`
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+	review, err := database.GetReview(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Repetitions != 1 || review.Interval != 1 {
+		t.Errorf(bodyError, "1 repetition with an interval of 1 day", review)
+	}
+}
+
+func TestGetQuoteCard(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/1/card.png", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{CardWidth: 400}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/card.png", getQuoteCard)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedHeader := "image/png"
+	if actualHeader := responseRecord.Header().Get("Content-type"); actualHeader != expectedHeader {
+		t.Errorf(headerError, expectedHeader, actualHeader)
+	}
+	card, err := png.Decode(responseRecord.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width := card.Bounds().Dx(); width != 400 {
+		t.Errorf(bodyError, 400, width)
+	}
+}
+
+func TestGetCardOfUnknownQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/42/card.png", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/card.png", getQuoteCard)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestGetReviewOfUnreviewedQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/1/review", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/review", getReview)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestGetReviewOfReviewedQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/1/review", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	_, err = database.NewReview(1).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/review", getReview)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedReview, err := database.GetReview(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal(expectedReview)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func initSubscriptions(t *testing.T) {
+	for _, recipient := range []string{"first@mail.com", "second@mail.com"} {
+		_, err := database.NewSubscription(recipient).Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetSubscriptions(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initSubscriptions(t)
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(getSubscriptions)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedSubscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal(expectedSubscriptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestSearchSubscriptions(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/?q=second", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initSubscriptions(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/", searchSubscriptions).Queries("q", "{search}")
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedSubscription, err := database.GetSubscription(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal([]db.Subscription{expectedSubscription})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestGetSubscriptionOfUnknownId(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/69", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", getSubscription)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := ""
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestGetSubscriptionOfKnownId(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initSubscriptions(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", getSubscription)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedSubscription, err := database.GetSubscription(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal(expectedSubscription)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestPostSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	data := url.Values{}
+	data.Add("Recipient", "to@mail.com")
+	data.Add("Schedule", "30 7 * * mon-fri")
+	data.Add("Strategy", "topic")
+	data.Add("TopicId", "2")
+	data.Add("TimeZone", "Europe/Berlin")
+	data.Add("SendTime", "07:30")
+	data.Add("Quiz", "true")
+	req, err := http.NewRequest(Post, "/", strings.NewReader(data.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/", postSubscription).Methods(Post)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusCreated
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := `{"Id": 1}`
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+	subscription, err := database.GetSubscription(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Schedule != "30 7 * * mon-fri" || subscription.TopicId != 2 ||
+		subscription.Count != 5 || subscription.TimeZone != "Europe/Berlin" ||
+		subscription.SendTime != "07:30" || !subscription.Quiz {
+		t.Errorf(bodyError, data, subscription)
+	}
+}
+
+func TestPostInvalidSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	invalid := []url.Values{
+		{"Schedule": {"daily"}},
+		{"Recipient": {"to@mail.com"}, "Schedule": {"yearly"}},
+		{"Recipient": {"to@mail.com"}, "Strategy": {"unknown"}},
+		{"Recipient": {"to@mail.com"}, "Count": {"many"}},
+		{"Recipient": {"to@mail.com"}, "TimeZone": {"Nowhere/Special"}},
+		{"Recipient": {"to@mail.com"}, "QuietStart": {"22:00"}},
+		{"Recipient": {"to@mail.com"}, "Quiz": {"maybe"}},
+		{"Recipient": {"to@mail.com"}, "Strategy": {"series"}},
+		{"Recipient": {"to@mail.com"}, "BookQueue": {"2;3"}},
+	}
+	for _, data := range invalid {
+		req, err := http.NewRequest(Post, "/", strings.NewReader(data.Encode()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		responseRecord := httptest.NewRecorder()
+		routerUnderTest := mux.NewRouter()
+		routerUnderTest.HandleFunc("/", postSubscription).Methods(Post)
+		// Act
+		routerUnderTest.ServeHTTP(responseRecord, req)
+		// Assert
+		expectedStatus := http.StatusBadRequest
+		if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+			t.Errorf(statusError, expectedStatus, actualStatus)
+		}
+	}
+}
+
+func TestPatchUnknownSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	data := url.Values{}
+	data.Add("Id", "69")
+	req, err := http.NewRequest(Patch, "/", strings.NewReader(data.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/", patchSubscription).Methods(Patch)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestPatchKnownSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	data := url.Values{}
+	expectedId := 1
+	data.Add("Id", fmt.Sprintf("%d", expectedId))
+	data.Add("Count", "3")
+	req, err := http.NewRequest(Patch, "/", strings.NewReader(data.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initSubscriptions(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/", patchSubscription).Methods(Patch)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := fmt.Sprintf(`{"Id": %d}`, expectedId)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+	subscription, err := database.GetSubscription(expectedId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription.Count != 3 || subscription.Recipient != "first@mail.com" {
+		t.Errorf(bodyError, data, subscription)
+	}
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Delete, "/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initSubscriptions(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", deleteSubscription).Methods(Delete)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	subscription, err := database.GetSubscription(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subscription != db.DefaultSubscription {
+		t.Errorf(bodyError, db.DefaultSubscription, subscription)
+	}
+}
+
+func TestDeleteUnknownSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Delete, "/69", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", deleteSubscription).Methods(Delete)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestDeleteQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Delete, "/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", deleteQuote).Methods(Delete)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	quote, err := database.GetQuote(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote != db.DefaultQuote {
+		t.Errorf(bodyError, db.DefaultQuote, quote)
+	}
+}
+
+func TestDeleteBookWithQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Delete, "/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	deletePolicy = db.RestrictPolicy
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", deleteBook).Methods(Delete)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusConflict
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book == db.DefaultBook {
+		t.Error("Expected the book to be kept but it was deleted")
+	}
+}
+
+func TestDeleteAuthorCascade(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Delete, "/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	deletePolicy = db.CascadePolicy
+	defer func() { deletePolicy = db.RestrictPolicy }()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", deleteAuthor).Methods(Delete)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := `{"Id": 1}`
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+	quote, err := database.GetQuote(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote != db.DefaultQuote {
+		t.Errorf(bodyError, db.DefaultQuote, quote)
+	}
+}
+
+func TestDeleteUnknownBook(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Delete, "/69", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", deleteBook).Methods(Delete)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+// initMessages creates a failed and a sent message in the outbox
+func initMessages(t *testing.T) {
+	for _, status := range []string{db.MessageFailed, db.MessageSent} {
+		message := database.NewMessage("to@mail.com", "Quote-reminder", "body", []int{1})
+		message.Status = status
+		message.Attempts = 5
+		_, err := message.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGetMessagesOfStatus(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/?status=failed", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initMessages(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/", getMessagesOfStatus).Queries("status", "{status}")
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedMessage, err := database.GetMessage(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal([]db.Message{expectedMessage})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestGetMessageOfUnknownId(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/69", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}", getMessage)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestRetryMessage(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Post, "/1/retry", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initMessages(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/retry", retryMessage)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	message, err := database.GetMessage(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Status != db.MessagePending || message.Attempts != 0 {
+		t.Errorf(bodyError, db.MessagePending, message.Status)
+	}
+}
+
+func TestRetrySentMessage(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Post, "/2/retry", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	initMessages(t)
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{id}/retry", retryMessage)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusConflict
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestPreviewReminder(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{Sender: "from@mail.com", Receiver: []string{"to@mail.com"}}
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(previewReminder)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	var preview mail.Preview
+	if err = json.Unmarshal(responseRecord.Body.Bytes(), &preview); err != nil {
+		t.Fatal(err)
+	}
+	if preview.Subject != "Quote-reminder" || len(preview.Quotes) != 2 || preview.Body == "" {
+		t.Errorf(bodyError, "rendered reminder of 2 quotes", responseRecord.Body.String())
+	}
+	messages, err := database.GetMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Errorf(bodyError, "no messages", messages)
+	}
+}
+
+func TestSendReminder(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Post, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{
+		Sender:   "from@mail.com",
+		Receiver: []string{"to@mail.com"},
+		Notifier: mail.NotifierConfig{Type: mail.MaildirNotifierType, Path: t.TempDir()},
+	}
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(sendReminder)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	messages, err := database.MessagesOfStatus(db.MessageSent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedJson, err := json.Marshal(messages)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody := string(expectedJson)
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestSendReminderWithoutQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Post, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{
+		Sender:   "from@mail.com",
+		Receiver: []string{"to@mail.com"},
+		TopicId:  69,
+	}
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(sendReminder)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusConflict
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestConfirmFeedback(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	var err error
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{FeedbackUrl: "http://localhost", FeedbackSecret: secret.New("secret")}
+	link := mailConfig.FeedbackLink(mail.FavoriteAction, "to@mail.com", 1)
+	req, err := http.NewRequest(Get, strings.TrimPrefix(link, "http://localhost/api/feedback"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{action}", confirmFeedback)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedHeader := "text/html; charset=utf-8"
+	if actualHeader := responseRecord.Header().Get("Content-type"); actualHeader != expectedHeader {
+		t.Errorf(headerError, expectedHeader, actualHeader)
+	}
+	if body := responseRecord.Body.String(); !strings.Contains(body, `<form method="post"`) {
+		t.Errorf(bodyError, "a confirmation form", body)
+	}
+	// opening the link does not apply the feedback
+	entries, err := database.FeedbackOf("to@mail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf(bodyError, "no feedback", entries)
+	}
+}
+
+func TestFeedback(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	var err error
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{FeedbackUrl: "http://localhost", FeedbackSecret: secret.New("secret")}
+	link := mailConfig.FeedbackLink(mail.FavoriteAction, "to@mail.com", 1)
+	req, err := http.NewRequest(Post, strings.TrimPrefix(link, "http://localhost/api/feedback"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{action}", feedback)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := `{"Action": "favorite"}`
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+	entries, err := database.FeedbackOf("to@mail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].QuoteId != 1 || entries[0].Kind != db.FeedbackFavorite {
+		t.Errorf(bodyError, "favorite feedback on quote 1", entries)
+	}
+}
+
+func TestFeedbackInvalidToken(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	var err error
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{FeedbackUrl: "http://localhost", FeedbackSecret: secret.New("secret")}
+	req, err := http.NewRequest(Post, "/unsubscribe?recipient=to%40mail.com&token=forged", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{action}", feedback)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusForbidden
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestFeedbackUnknownAction(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	var err error
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	mailConfig = mail.Config{FeedbackUrl: "http://localhost", FeedbackSecret: secret.New("secret")}
+	req, err := http.NewRequest(Get, "/like?recipient=to%40mail.com&quote=1&token=x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRecord := httptest.NewRecorder()
+	routerUnderTest := mux.NewRouter()
+	routerUnderTest.HandleFunc("/{action}", feedback)
+	// Act
+	routerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusNotFound
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
+
+func TestGetQuotesOnThisDay(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/?date=2021-01-03", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(getQuotesOnThisDay)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	// both quotes of the test database have been recorded on 1999-01-01
+	var quotes []db.Quote
+	if err = json.Unmarshal(responseRecord.Body.Bytes(), &quotes); err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Errorf(bodyError, "2 quotes", responseRecord.Body.String())
+	}
+}
+
+func TestGetQuotesOnThisDayWithoutMatches(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/?date=2021-06-01", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(getQuotesOnThisDay)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusOK
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+	expectedBody := "null"
+	if actualBody := responseRecord.Body.String(); actualBody != expectedBody {
+		t.Errorf(bodyError, expectedBody, actualBody)
+	}
+}
+
+func TestGetQuotesOnThisDayInvalidDate(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	req, err := http.NewRequest(Get, "/?date=01.03.2021", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database, err = db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	responseRecord := httptest.NewRecorder()
+	handlerUnderTest := http.HandlerFunc(getQuotesOnThisDay)
+	// Act
+	handlerUnderTest.ServeHTTP(responseRecord, req)
+	// Assert
+	expectedStatus := http.StatusBadRequest
+	if actualStatus := responseRecord.Code; actualStatus != expectedStatus {
+		t.Errorf(statusError, expectedStatus, actualStatus)
+	}
+}
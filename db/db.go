@@ -2,6 +2,10 @@ package quote
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +20,55 @@ type DAO interface {
 	// Filter checks if the given filters strings match to the current dao.
 	// Returns a true if it matches the provided filters otherwise false
 	Filter(filters ...string) bool
+	// Delete the DAO from the Database together with the entries which only
+	// describe it, returning an error if Delete failed
+	Delete() error
+}
+
+// Policies for deleting an entry which other entries depend on
+const (
+	// RestrictPolicy refuses to delete the entry (see ErrDependents)
+	RestrictPolicy = "restrict"
+	// CascadePolicy deletes the dependent entries together with the entry
+	CascadePolicy = "cascade"
+)
+
+// ErrDependents is returned when an entry is not deleted, as other entries
+// depend on it
+var ErrDependents = errors.New("other entries depend on it")
+
+// ParseDeletePolicy returns the delete policy `policy`, which defaults to
+// RestrictPolicy
+func ParseDeletePolicy(policy string) (string, error) {
+	switch policy = strings.ToLower(policy); policy {
+	case "":
+		return RestrictPolicy, nil
+	case RestrictPolicy, CascadePolicy:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown delete policy %q", policy)
+}
+
+// restrictedDelete deletes the entry `id` of the kind `name` with
+// `restrictStmt`, which only deletes it if no entries depend on it. If it was
+// not deleted `dependentsStmt` counts the entries depending on it for the
+// error.
+func restrictedDelete(restrictStmt, dependentsStmt *sql.Stmt, name string, id int) (err error) {
+	res, err := restrictStmt.Exec(id)
+	if err != nil {
+		return
+	}
+	if deleted, err := res.RowsAffected(); err != nil || deleted > 0 {
+		return err
+	}
+	var count int
+	if err = dependentsStmt.QueryRow(id).Scan(&count); err != nil {
+		return
+	}
+	if count > 0 {
+		return fmt.Errorf("cannot delete %s %d, %d %w", name, id, count, ErrDependents)
+	}
+	return
 }
 
 type Quote struct {
@@ -25,12 +78,14 @@ type Quote struct {
 	Page       int
 	RecordDate time.Time
 	stmt       *sql.Stmt
+	deleteStmt *sql.Stmt
 }
 
 var DefaultQuote Quote = Quote{}
 
 func (db Database) NewQuote(book Book) (quote Quote) {
 	quote.stmt = db.insertQuoteStmt
+	quote.deleteStmt = db.deleteQuoteStmt
 	quote.Book = book
 	return
 }
@@ -55,6 +110,12 @@ func (quote Quote) Commit() (id int, err error) {
 	return
 }
 
+// Delete the Quote from the Database with its deliveries, review and feedback
+func (quote Quote) Delete() (err error) {
+	_, err = quote.deleteStmt.Exec(quote.Id)
+	return
+}
+
 func (quote Quote) Filter(filters ...string) bool {
 	for _, filter := range filters {
 		if strings.Contains(quote.Quote, filter) {
@@ -65,20 +126,26 @@ func (quote Quote) Filter(filters ...string) bool {
 }
 
 type Book struct {
-	Id          int
-	Author      Author
-	Topic       Topic
-	Title       string
-	ISBN        sql.NullString
-	Language    Language
-	ReleaseDate time.Time
-	stmt        *sql.Stmt
+	Id             int
+	Author         Author
+	Topic          Topic
+	Title          string
+	ISBN           sql.NullString
+	Language       Language
+	ReleaseDate    time.Time
+	stmt           *sql.Stmt
+	deleteStmt     *sql.Stmt
+	dependentsStmt *sql.Stmt
+	restrictStmt   *sql.Stmt
 }
 
 var DefaultBook Book = Book{}
 
 func (db Database) NewBook(author Author, topic Topic, language Language) (book Book) {
 	book.stmt = db.insertBookStmt
+	book.deleteStmt = db.deleteBookStmt
+	book.dependentsStmt = db.dependentsOfBookStmt
+	book.restrictStmt = db.restrictedDeleteBookStmt
 	book.Author = author
 	book.Topic = topic
 	book.Language = language
@@ -115,6 +182,19 @@ func (book Book) Commit() (id int, err error) {
 	return
 }
 
+// Delete the Book from the Database, which fails with ErrDependents while
+// quotes or subscriptions depend on it (see DeleteCascade)
+func (book Book) Delete() error {
+	return restrictedDelete(book.restrictStmt, book.dependentsStmt, "book", book.Id)
+}
+
+// DeleteCascade deletes the Book from the Database with its quotes and the
+// subscriptions to it
+func (book Book) DeleteCascade() (err error) {
+	_, err = book.deleteStmt.Exec(book.Id)
+	return
+}
+
 func (book Book) Filter(filters ...string) bool {
 	for _, filter := range filters {
 		if strings.Contains(book.Title, filter) || strings.Contains(book.ISBN.String, filter) {
@@ -125,15 +205,21 @@ func (book Book) Filter(filters ...string) bool {
 }
 
 type Author struct {
-	Id   int
-	Name string
-	stmt *sql.Stmt
+	Id             int
+	Name           string
+	stmt           *sql.Stmt
+	deleteStmt     *sql.Stmt
+	dependentsStmt *sql.Stmt
+	restrictStmt   *sql.Stmt
 }
 
 var DefaultAuthor Author = Author{}
 
 func (db Database) NewAuthor() (author Author) {
 	author.stmt = db.insertAuthorStmt
+	author.deleteStmt = db.deleteAuthorStmt
+	author.dependentsStmt = db.dependentsOfAuthorStmt
+	author.restrictStmt = db.restrictedDeleteAuthorStmt
 	return
 }
 
@@ -153,6 +239,19 @@ func (author Author) Commit() (id int, err error) {
 	return
 }
 
+// Delete the Author from the Database, which fails with ErrDependents while
+// books or subscriptions depend on it (see DeleteCascade)
+func (author Author) Delete() error {
+	return restrictedDelete(author.restrictStmt, author.dependentsStmt, "author", author.Id)
+}
+
+// DeleteCascade deletes the Author from the Database with their books and
+// the subscriptions to them
+func (author Author) DeleteCascade() (err error) {
+	_, err = author.deleteStmt.Exec(author.Id)
+	return
+}
+
 func (author Author) Filter(filters ...string) bool {
 	for _, filter := range filters {
 		if strings.Contains(author.Name, filter) {
@@ -163,15 +262,21 @@ func (author Author) Filter(filters ...string) bool {
 }
 
 type Topic struct {
-	Id    int
-	Topic string
-	stmt  *sql.Stmt
+	Id             int
+	Topic          string
+	stmt           *sql.Stmt
+	deleteStmt     *sql.Stmt
+	dependentsStmt *sql.Stmt
+	restrictStmt   *sql.Stmt
 }
 
 var DefaultTopic Topic = Topic{}
 
 func (db Database) NewTopic() (topic Topic) {
 	topic.stmt = db.insertTopicStmt
+	topic.deleteStmt = db.deleteTopicStmt
+	topic.dependentsStmt = db.dependentsOfTopicStmt
+	topic.restrictStmt = db.restrictedDeleteTopicStmt
 	return
 }
 
@@ -191,6 +296,19 @@ func (topic Topic) Commit() (id int, err error) {
 	return
 }
 
+// Delete the Topic from the Database, which fails with ErrDependents while
+// books or subscriptions depend on it (see DeleteCascade)
+func (topic Topic) Delete() error {
+	return restrictedDelete(topic.restrictStmt, topic.dependentsStmt, "topic", topic.Id)
+}
+
+// DeleteCascade deletes the Topic from the Database with its books and the
+// subscriptions to it
+func (topic Topic) DeleteCascade() (err error) {
+	_, err = topic.deleteStmt.Exec(topic.Id)
+	return
+}
+
 func (topic Topic) Filter(filters ...string) bool {
 	for _, filter := range filters {
 		if strings.Contains(topic.Topic, filter) {
@@ -201,15 +319,21 @@ func (topic Topic) Filter(filters ...string) bool {
 }
 
 type Language struct {
-	Id       int
-	Language string
-	stmt     *sql.Stmt
+	Id             int
+	Language       string
+	stmt           *sql.Stmt
+	deleteStmt     *sql.Stmt
+	dependentsStmt *sql.Stmt
+	restrictStmt   *sql.Stmt
 }
 
 var DefaultLanguage Language = Language{}
 
 func (db Database) NewLanguage() (language Language) {
 	language.stmt = db.insertLanguageStmt
+	language.deleteStmt = db.deleteLanguageStmt
+	language.dependentsStmt = db.dependentsOfLanguageStmt
+	language.restrictStmt = db.restrictedDeleteLanguageStmt
 	return
 }
 
@@ -229,6 +353,19 @@ func (language Language) Commit() (id int, err error) {
 	return
 }
 
+// Delete the Language from the Database, which fails with ErrDependents
+// while books or subscriptions depend on it (see DeleteCascade)
+func (language Language) Delete() error {
+	return restrictedDelete(language.restrictStmt, language.dependentsStmt, "language", language.Id)
+}
+
+// DeleteCascade deletes the Language from the Database with its books and
+// the subscriptions to it
+func (language Language) DeleteCascade() (err error) {
+	_, err = language.deleteStmt.Exec(language.Id)
+	return
+}
+
 func (language Language) Filter(filters ...string) bool {
 	for _, filter := range filters {
 		if strings.Contains(language.Language, filter) {
@@ -238,32 +375,510 @@ func (language Language) Filter(filters ...string) bool {
 	return false
 }
 
+// Delivery records that a quote has been sent to a recipient
+type Delivery struct {
+	Id           int
+	QuoteId      int
+	Recipient    string
+	DeliveryDate time.Time
+	stmt         *sql.Stmt
+	deleteStmt   *sql.Stmt
+}
+
+var DefaultDelivery Delivery = Delivery{}
+
+func (db Database) NewDelivery(quoteId int, recipient string) (delivery Delivery) {
+	delivery.stmt = db.insertDeliveryStmt
+	delivery.deleteStmt = db.deleteDeliveryStmt
+	delivery.QuoteId = quoteId
+	delivery.Recipient = recipient
+	delivery.DeliveryDate = time.Now()
+	return
+}
+
+func (delivery Delivery) Commit() (id int, err error) {
+	if delivery.Id == 0 { // Insert
+		res, err := delivery.stmt.Exec(delivery.QuoteId, delivery.Recipient,
+			delivery.DeliveryDate)
+		if err != nil {
+			return -1, err
+		}
+		insertedId, e := res.LastInsertId()
+		id = int(insertedId)
+		err = e
+	} else { // Update
+		_, err = delivery.stmt.Exec(delivery.QuoteId, delivery.Recipient,
+			delivery.DeliveryDate, delivery.Id)
+		id = delivery.Id
+	}
+	return
+}
+
+// Delete the Delivery from the Database
+func (delivery Delivery) Delete() (err error) {
+	_, err = delivery.deleteStmt.Exec(delivery.Id)
+	return
+}
+
+func (delivery Delivery) Filter(filters ...string) bool {
+	for _, filter := range filters {
+		if strings.Contains(delivery.Recipient, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// Review stores the spaced repetition (SM-2) state of a quote
+type Review struct {
+	Id          int
+	QuoteId     int
+	EaseFactor  float64
+	Interval    int // in days
+	Repetitions int
+	DueDate     time.Time
+	stmt        *sql.Stmt
+	deleteStmt  *sql.Stmt
+}
+
+var DefaultReview Review = Review{}
+
+func (db Database) NewReview(quoteId int) (review Review) {
+	review.stmt = db.insertReviewStmt
+	review.deleteStmt = db.deleteReviewStmt
+	review.QuoteId = quoteId
+	review.EaseFactor = 2.5
+	review.DueDate = time.Now()
+	return
+}
+
+func (review Review) Commit() (id int, err error) {
+	// due dates are compared in the database, which requires a uniform
+	// representation
+	dueDate := review.DueDate.UTC().Truncate(time.Second)
+	if review.Id == 0 { // Insert
+		res, err := review.stmt.Exec(review.QuoteId, review.EaseFactor,
+			review.Interval, review.Repetitions, dueDate)
+		if err != nil {
+			return -1, err
+		}
+		insertedId, e := res.LastInsertId()
+		id = int(insertedId)
+		err = e
+	} else { // Update
+		_, err = review.stmt.Exec(review.QuoteId, review.EaseFactor,
+			review.Interval, review.Repetitions, dueDate, review.Id)
+		id = review.Id
+	}
+	return
+}
+
+// Delete the Review from the Database, which resets the review state of its
+// quote
+func (review Review) Delete() (err error) {
+	_, err = review.deleteStmt.Exec(review.Id)
+	return
+}
+
+// Grade returns the Review updated with the SM-2 algorithm for a recall of
+// quality `grade` (0: complete blackout to 5: perfect response) at `date`.
+func (review Review) Grade(grade int, date time.Time) (Review, error) {
+	if grade < 0 || grade > 5 {
+		return review, fmt.Errorf("grade %d is not within [0, 5]", grade)
+	}
+	if grade < 3 {
+		review.Repetitions = 0
+		review.Interval = 1
+	} else {
+		switch review.Repetitions {
+		case 0:
+			review.Interval = 1
+		case 1:
+			review.Interval = 6
+		default:
+			review.Interval = int(math.Round(float64(review.Interval) * review.EaseFactor))
+		}
+		review.Repetitions += 1
+	}
+	q := float64(5 - grade)
+	review.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if review.EaseFactor < 1.3 {
+		review.EaseFactor = 1.3
+	}
+	review.DueDate = date.AddDate(0, 0, review.Interval)
+	return review, nil
+}
+
+// Subscription of a recipient to reminders with an individual schedule,
+// selection strategy and filters. A filter with the Id 0 is not applied.
+type Subscription struct {
+	Id         int
+	Recipient  string
+	Schedule   string
+	Count      int
+	Strategy   string
+	TopicId    int
+	AuthorId   int
+	LanguageId int
+	BookId     int
+	// TimeZone is the IANA time zone the Schedule is evaluated in, empty
+	// meaning the local time zone of the server
+	TimeZone string
+	// SendTime (HH:MM) replaces the time of day of the Schedule if set
+	SendTime string
+	// QuietStart and QuietEnd (HH:MM) are the quiet hours in which no
+	// reminders are sent if set
+	QuietStart string
+	QuietEnd   string
+	// LastSent is the point in time the last reminder of the subscription
+	// was sent, it is not changed by Commit (see SubscriptionSent)
+	LastSent time.Time
+	// Quiz blanks out key words of the quotes of the reminders
+	Quiz bool
+	// BookQueue holds the comma separated ids of the books which follow
+	// BookId in a series
+	BookQueue string
+	// Cursor is the id and CursorPage the page of the last quote of BookId
+	// which was sent in a series. Together they are the position in the
+	// book, which is kept when the quote is deleted or moved to another page.
+	// They are not changed by Commit (see SubscriptionProgress).
+	Cursor     int
+	CursorPage int
+	stmt       *sql.Stmt
+	deleteStmt *sql.Stmt
+}
+
+var DefaultSubscription Subscription = Subscription{}
+
+func (db Database) NewSubscription(recipient string) (subscription Subscription) {
+	subscription.stmt = db.insertSubscriptionStmt
+	subscription.deleteStmt = db.deleteSubscriptionStmt
+	subscription.Recipient = recipient
+	subscription.Schedule = "daily"
+	subscription.Count = 5
+	subscription.Strategy = "random"
+	return
+}
+
+func (subscription Subscription) Commit() (id int, err error) {
+	if subscription.Id == 0 { // Insert
+		res, err := subscription.stmt.Exec(subscription.Recipient,
+			subscription.Schedule, subscription.Count, subscription.Strategy,
+			subscription.TopicId, subscription.AuthorId,
+			subscription.LanguageId, subscription.BookId,
+			subscription.TimeZone, subscription.SendTime,
+			subscription.QuietStart, subscription.QuietEnd,
+			subscription.Quiz, subscription.BookQueue)
+		if err != nil {
+			return -1, err
+		}
+		insertedId, e := res.LastInsertId()
+		id = int(insertedId)
+		err = e
+	} else { // Update
+		_, err = subscription.stmt.Exec(subscription.Recipient,
+			subscription.Schedule, subscription.Count, subscription.Strategy,
+			subscription.TopicId, subscription.AuthorId,
+			subscription.LanguageId, subscription.BookId,
+			subscription.TimeZone, subscription.SendTime,
+			subscription.QuietStart, subscription.QuietEnd,
+			subscription.Quiz, subscription.BookQueue, subscription.Id)
+		id = subscription.Id
+	}
+	return
+}
+
+// Delete the Subscription from the Database
+func (subscription Subscription) Delete() (err error) {
+	_, err = subscription.deleteStmt.Exec(subscription.Id)
+	return
+}
+
+// Queue returns the ids of the books which follow BookId in a series
+func (subscription Subscription) Queue() ([]int, error) {
+	return splitIds(subscription.BookQueue)
+}
+
+// SetQueue sets the ids of the books which follow BookId in a series
+func (subscription *Subscription) SetQueue(bookIds []int) {
+	subscription.BookQueue = joinIds(bookIds)
+}
+
+func (subscription Subscription) Filter(filters ...string) bool {
+	for _, filter := range filters {
+		if strings.Contains(subscription.Recipient, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// States of a Message in the outbox
+const (
+	MessagePending = "pending"
+	MessageSent    = "sent"
+	MessageFailed  = "failed"
+)
+
+// Message is a rendered reminder in the outbox, which is delivered to its
+// Recipient by the mail service
+type Message struct {
+	Id        int
+	Recipient string
+	Subject   string
+	Body      string
+	// Text is the plain text version of the Body, which is delivered to
+	// recipients without mail
+	Text string
+	// comma separated ids of the quotes contained in the Message
+	QuoteIds string
+	// SubscriptionId is the id of the subscription the Message was sent
+	// for, 0 otherwise
+	SubscriptionId int
+	Status         string
+	Attempts       int
+	NextAttempt    time.Time
+	LastError      string
+	CreatedDate    time.Time
+	stmt           *sql.Stmt
+	deleteStmt     *sql.Stmt
+}
+
+var DefaultMessage Message = Message{}
+
+func (db Database) NewMessage(recipient, subject, body string, quoteIds []int) (message Message) {
+	message.stmt = db.insertMessageStmt
+	message.deleteStmt = db.deleteMessageStmt
+	message.Recipient = recipient
+	message.Subject = subject
+	message.Body = body
+	message.QuoteIds = joinIds(quoteIds)
+	message.Status = MessagePending
+	message.CreatedDate = time.Now()
+	message.NextAttempt = message.CreatedDate
+	return
+}
+
+func (message Message) Commit() (id int, err error) {
+	// the next attempts are compared in the database, which requires a
+	// uniform representation
+	nextAttempt := message.NextAttempt.UTC().Truncate(time.Second)
+	if message.Id == 0 { // Insert
+		res, err := message.stmt.Exec(message.Recipient, message.Subject,
+			message.Body, message.QuoteIds, message.Status, message.Attempts,
+			nextAttempt, message.LastError, message.CreatedDate, message.Text,
+			message.SubscriptionId)
+		if err != nil {
+			return -1, err
+		}
+		insertedId, e := res.LastInsertId()
+		id = int(insertedId)
+		err = e
+	} else { // Update
+		_, err = message.stmt.Exec(message.Recipient, message.Subject,
+			message.Body, message.QuoteIds, message.Status, message.Attempts,
+			nextAttempt, message.LastError, message.CreatedDate, message.Text,
+			message.SubscriptionId, message.Id)
+		id = message.Id
+	}
+	return
+}
+
+// Delete the Message from the outbox
+func (message Message) Delete() (err error) {
+	_, err = message.deleteStmt.Exec(message.Id)
+	return
+}
+
+func (message Message) Filter(filters ...string) bool {
+	for _, filter := range filters {
+		if strings.Contains(message.Recipient, filter) ||
+			strings.Contains(message.Subject, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// Quotes returns the ids of the quotes contained in the Message
+func (message Message) Quotes() (ids []int, err error) {
+	return splitIds(message.QuoteIds)
+}
+
+// joinIds returns the comma separated list of `ids`
+func joinIds(ids []int) string {
+	var values []string
+	for _, id := range ids {
+		values = append(values, strconv.Itoa(id))
+	}
+	return strings.Join(values, ",")
+}
+
+// splitIds returns the ids of the comma separated list `values`
+func splitIds(values string) (ids []int, err error) {
+	if values == "" {
+		return
+	}
+	for _, val := range strings.Split(values, ",") {
+		var id int
+		id, err = strconv.Atoi(strings.TrimSpace(val))
+		if err != nil {
+			return
+		}
+		ids = append(ids, id)
+	}
+	return
+}
+
+// Tally is the number of quotes of an entry (e.g. a topic or author) with its
+// Id and Name
+type Tally struct {
+	Id    int
+	Name  string
+	Count int
+}
+
+// Size of the quote collection
+type Size struct {
+	Quotes    int
+	Books     int
+	Authors   int
+	Topics    int
+	Languages int
+}
+
+// Kinds of Feedback a recipient can give on a quote
+const (
+	FeedbackFavorite = "favorite"
+	FeedbackLess     = "less"
+	FeedbackSnooze   = "snooze"
+)
+
+// Feedback of a recipient on a quote of a reminder. Until is only used by
+// snoozes, which suspend the quote until then.
+type Feedback struct {
+	Id           int
+	QuoteId      int
+	Recipient    string
+	Kind         string
+	Until        time.Time
+	FeedbackDate time.Time
+	stmt         *sql.Stmt
+	deleteStmt   *sql.Stmt
+}
+
+var DefaultFeedback Feedback = Feedback{}
+
+func (db Database) NewFeedback(quoteId int, recipient, kind string) (feedback Feedback) {
+	feedback.stmt = db.insertFeedbackStmt
+	feedback.deleteStmt = db.deleteFeedbackStmt
+	feedback.QuoteId = quoteId
+	feedback.Recipient = recipient
+	feedback.Kind = kind
+	feedback.FeedbackDate = time.Now()
+	return
+}
+
+func (feedback Feedback) Commit() (id int, err error) {
+	until := feedback.Until.UTC().Truncate(time.Second)
+	if feedback.Id == 0 { // Insert
+		res, err := feedback.stmt.Exec(feedback.QuoteId, feedback.Recipient,
+			feedback.Kind, until, feedback.FeedbackDate)
+		if err != nil {
+			return -1, err
+		}
+		insertedId, e := res.LastInsertId()
+		id = int(insertedId)
+		err = e
+	} else { // Update
+		_, err = feedback.stmt.Exec(feedback.QuoteId, feedback.Recipient,
+			feedback.Kind, until, feedback.FeedbackDate, feedback.Id)
+		id = feedback.Id
+	}
+	return
+}
+
+// Delete the Feedback from the Database
+func (feedback Feedback) Delete() (err error) {
+	_, err = feedback.deleteStmt.Exec(feedback.Id)
+	return
+}
+
+func (feedback Feedback) Filter(filters ...string) bool {
+	for _, filter := range filters {
+		if strings.Contains(feedback.Recipient, filter) ||
+			strings.Contains(feedback.Kind, filter) {
+			return true
+		}
+	}
+	return false
+}
+
 type Database struct {
 	connection *sql.DB
 	// select statements
-	selectBooksStmt     *sql.Stmt
-	selectTopicsStmt    *sql.Stmt
-	selectAuthorsStmt   *sql.Stmt
-	selectQuotesStmt    *sql.Stmt
-	selectLanguagesStmt *sql.Stmt
+	selectBooksStmt         *sql.Stmt
+	selectTopicsStmt        *sql.Stmt
+	selectAuthorsStmt       *sql.Stmt
+	selectQuotesStmt        *sql.Stmt
+	selectLanguagesStmt     *sql.Stmt
+	selectDeliveriesStmt    *sql.Stmt
+	selectSubscriptionsStmt *sql.Stmt
+	selectMessagesStmt      *sql.Stmt
+	selectFeedbackStmt      *sql.Stmt
 	// select by id statements
-	selectBookStmt     *sql.Stmt
-	selectTopicStmt    *sql.Stmt
-	selectAuthorStmt   *sql.Stmt
-	selectQuoteStmt    *sql.Stmt
-	selectLanguageStmt *sql.Stmt
+	selectBookStmt         *sql.Stmt
+	selectTopicStmt        *sql.Stmt
+	selectAuthorStmt       *sql.Stmt
+	selectQuoteStmt        *sql.Stmt
+	selectLanguageStmt     *sql.Stmt
+	selectReviewStmt       *sql.Stmt
+	selectDeliveryStmt     *sql.Stmt
+	selectSubscriptionStmt *sql.Stmt
+	selectMessageStmt      *sql.Stmt
 	// insert statements
-	insertBookStmt     *sql.Stmt
-	insertTopicStmt    *sql.Stmt
-	insertAuthorStmt   *sql.Stmt
-	insertQuoteStmt    *sql.Stmt
-	insertLanguageStmt *sql.Stmt
+	insertBookStmt         *sql.Stmt
+	insertTopicStmt        *sql.Stmt
+	insertAuthorStmt       *sql.Stmt
+	insertQuoteStmt        *sql.Stmt
+	insertLanguageStmt     *sql.Stmt
+	insertDeliveryStmt     *sql.Stmt
+	insertReviewStmt       *sql.Stmt
+	insertSubscriptionStmt *sql.Stmt
+	insertMessageStmt      *sql.Stmt
+	insertFeedbackStmt     *sql.Stmt
 	// update statements
-	updateBookStmt     *sql.Stmt
-	updateTopicStmt    *sql.Stmt
-	updateAuthorStmt   *sql.Stmt
-	updateQuoteStmt    *sql.Stmt
-	updateLanguageStmt *sql.Stmt
+	updateBookStmt         *sql.Stmt
+	updateTopicStmt        *sql.Stmt
+	updateAuthorStmt       *sql.Stmt
+	updateQuoteStmt        *sql.Stmt
+	updateLanguageStmt     *sql.Stmt
+	updateDeliveryStmt     *sql.Stmt
+	updateReviewStmt       *sql.Stmt
+	updateSubscriptionStmt *sql.Stmt
+	updateMessageStmt      *sql.Stmt
+	updateFeedbackStmt     *sql.Stmt
+	lastSentStmt           *sql.Stmt
+	progressStmt           *sql.Stmt
+	// delete statements
+	deleteBookStmt               *sql.Stmt
+	deleteTopicStmt              *sql.Stmt
+	deleteAuthorStmt             *sql.Stmt
+	deleteQuoteStmt              *sql.Stmt
+	deleteLanguageStmt           *sql.Stmt
+	deleteDeliveryStmt           *sql.Stmt
+	deleteReviewStmt             *sql.Stmt
+	deleteSubscriptionStmt       *sql.Stmt
+	deleteMessageStmt            *sql.Stmt
+	deleteFeedbackStmt           *sql.Stmt
+	dependentsOfBookStmt         *sql.Stmt
+	dependentsOfTopicStmt        *sql.Stmt
+	dependentsOfAuthorStmt       *sql.Stmt
+	dependentsOfLanguageStmt     *sql.Stmt
+	restrictedDeleteBookStmt     *sql.Stmt
+	restrictedDeleteTopicStmt    *sql.Stmt
+	restrictedDeleteAuthorStmt   *sql.Stmt
+	restrictedDeleteLanguageStmt *sql.Stmt
 	// related entries statements
 	relatedQuotesOfBookStmt     *sql.Stmt
 	relatedBooksOfTopicStmt     *sql.Stmt
@@ -273,24 +888,51 @@ type Database struct {
 	relatedBooksOfLanguageStmt  *sql.Stmt
 	relatedQuotesOfLanguageStmt *sql.Stmt
 	// searches
-	searchTopicsStmt    *sql.Stmt
-	searchAuthorsStmt   *sql.Stmt
-	searchLanguagesStmt *sql.Stmt
-	searchBooksStmt     *sql.Stmt
-	searchQuotesStmt    *sql.Stmt
+	searchTopicsStmt        *sql.Stmt
+	searchAuthorsStmt       *sql.Stmt
+	searchLanguagesStmt     *sql.Stmt
+	searchBooksStmt         *sql.Stmt
+	searchQuotesStmt        *sql.Stmt
+	searchDeliveriesStmt    *sql.Stmt
+	searchSubscriptionsStmt *sql.Stmt
+	messagesOfStatusStmt    *sql.Stmt
+	pendingMessagesStmt     *sql.Stmt
+	pendingMessagesOfStmt   *sql.Stmt
+	feedbackOfStmt          *sql.Stmt
+	// aggregations
+	deliveryCountsStmt  *sql.Stmt
+	dueQuotesStmt       *sql.Stmt
+	recordedQuotesStmt  *sql.Stmt
+	quotesPerTopicStmt  *sql.Stmt
+	quotesPerAuthorStmt *sql.Stmt
+	favoriteQuotesStmt  *sql.Stmt
+	collectionSizeStmt  *sql.Stmt
 }
 
 // Connect to an sqlite Database located at `filename` This function ensures
-// that the file will be created if it does not exist, create the required
-// tables if it can successfully open the file
+// that the file will be created if it does not exist and migrates it to the
+// latest schema version (see Migrate) if it can successfully open the file
 func Connect(filename string) (db *Database, err error) {
-	db = new(Database)
-	db.connection, err = sql.Open("sqlite3", filename)
+	db, err = Open(filename)
 	if err != nil {
 		return
 	}
-	db.Init()
-	db.Prepare()
+	_, err = db.Migrate()
+	if err == nil {
+		err = db.Prepare()
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return
+}
+
+// Open the sqlite Database located at `filename` without migrating it, so that
+// only the migration status can be queried until it is migrated
+func Open(filename string) (db *Database, err error) {
+	db = new(Database)
+	db.connection, err = sql.Open("sqlite3", filename)
 	return
 }
 
@@ -300,65 +942,6 @@ func (db *Database) Close() {
 	db.connection.Close()
 }
 
-// create tables
-const (
-	createBook = `CREATE TABLE Books (
-Id INTEGER PRIMARY KEY AUTOINCREMENT,
-AuthorId INTEGER NOT NULL,
-TopicId INTEGER NOT NULL,
-ISBN varchar UNIQUE,
-Title varchar NOT NULL,
-LanguageId INTEGER NOT NULL,
-ReleaseDate date NOT NULL,
-FOREIGN KEY (AuthorId) REFERENCES Authors(Id),
-FOREIGN KEY (TopicId) REFERENCES Topics(Id),
-FOREIGN KEY (LanguageId) REFERENCES Languages(Id)
-);`
-	createTopic = `CREATE TABLE Topics (
-Id INTEGER PRIMARY KEY AUTOINCREMENT,
-Topic varchar NOT NULL UNIQUE
-);`
-	createAuthor = `CREATE TABLE Authors (
-Id INTEGER PRIMARY KEY AUTOINCREMENT,
-Name varchar NOT NULL UNIQUE
-);`
-	createQuote = `CREATE TABLE Quotes (
-Id INTEGER PRIMARY KEY AUTOINCREMENT,
-BookId INTEGER NOT NULL,
-Quote varchar NOT NULL,
-Page INTEGER NOT NULL,
-RecordDate date NOT NULL DEFAULT CURRENT_DATE,
-FOREIGN KEY (BookId) REFERENCES Books(Id)
-);`
-	createLanguage = `CREATE TABLE Languages (
-Id INTEGER PRIMARY KEY AUTOINCREMENT,
-Language varchar NOT NULL UNIQUE
-);`
-)
-
-// Initialize the Database by creating the tables required for quote.
-func (db *Database) Init() (err error) {
-	// create tables
-	_, err = db.connection.Exec(createTopic)
-	if err != nil {
-		return
-	}
-	_, err = db.connection.Exec(createAuthor)
-	if err != nil {
-		return
-	}
-	_, err = db.connection.Exec(createLanguage)
-	if err != nil {
-		return
-	}
-	_, err = db.connection.Exec(createBook)
-	if err != nil {
-		return
-	}
-	_, err = db.connection.Exec(createQuote)
-	return
-}
-
 // Prepare Statements
 const (
 	selectBooks = `SELECT * FROM Books
@@ -372,7 +955,11 @@ JOIN Books ON Quotes.BookId = Books.Id
 JOIN Authors ON Books.AuthorId = Authors.Id
 JOIN Topics ON Books.TopicId = Topics.Id
 JOIN Languages ON Books.LanguageId = Languages.Id;`
-	selectLanguages = "SELECT * FROM Languages;"
+	selectLanguages     = "SELECT * FROM Languages;"
+	selectDeliveries    = "SELECT * FROM Deliveries ORDER BY DeliveryDate;"
+	selectSubscriptions = "SELECT * FROM Subscriptions;"
+	selectMessages      = "SELECT * FROM Outbox ORDER BY CreatedDate;"
+	selectFeedback      = "SELECT * FROM Feedback ORDER BY FeedbackDate;"
 )
 
 const (
@@ -389,23 +976,82 @@ JOIN Authors ON Books.AuthorId = Authors.Id
 JOIN Topics ON Books.TopicId = Topics.Id
 JOIN Languages ON Books.LanguageId = Languages.Id
 WHERE Quotes.Id = ?;`
-	selectLanguage = "SELECT * FROM Languages WHERE Id = ?;"
+	selectLanguage     = "SELECT * FROM Languages WHERE Id = ?;"
+	selectReview       = "SELECT * FROM Reviews WHERE QuoteId = ?;"
+	selectDelivery     = "SELECT * FROM Deliveries WHERE Id = ?;"
+	selectSubscription = "SELECT * FROM Subscriptions WHERE Id = ?;"
+	selectMessage      = "SELECT * FROM Outbox WHERE Id = ?;"
+)
+
+const (
+	insertBook         = "INSERT INTO Books (AuthorId, TopicId, ISBN, Title, LanguageId, ReleaseDate) VALUES (?, ?, ?, ?, ?, ?);"
+	insertTopic        = "INSERT INTO Topics (Topic) VALUES (?);"
+	insertAuthor       = "INSERT INTO Authors (Name) VALUES (?);"
+	insertQuote        = "INSERT INTO Quotes (BookId, Quote, Page) VALUES (?, ?, ?);"
+	insertLanguage     = "INSERT INTO Languages (Language) VALUES (?);"
+	insertDelivery     = "INSERT INTO Deliveries (QuoteId, Recipient, DeliveryDate) VALUES (?, ?, ?);"
+	insertReview       = "INSERT INTO Reviews (QuoteId, EaseFactor, Interval, Repetitions, DueDate) VALUES (?, ?, ?, ?, ?);"
+	insertSubscription = "INSERT INTO Subscriptions (Recipient, Schedule, Count, Strategy, TopicId, AuthorId, LanguageId, BookId, TimeZone, SendTime, QuietStart, QuietEnd, Quiz, BookQueue) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);"
+	insertMessage      = "INSERT INTO Outbox (Recipient, Subject, Body, QuoteIds, Status, Attempts, NextAttempt, LastError, CreatedDate, Text, SubscriptionId) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);"
+	insertFeedback     = "INSERT INTO Feedback (QuoteId, Recipient, Kind, Until, FeedbackDate) VALUES (?, ?, ?, ?, ?);"
+)
+
+const (
+	updateBook         = "UPDATE Books SET AuthorId = ?, TopicId = ?, ISBN = ?, Title = ?, LanguageId = ?, ReleaseDate = ? WHERE Id = ?;"
+	updateTopic        = "UPDATE Topics SET Topic = ? WHERE Id = ?;"
+	updateAuthor       = "UPDATE Authors SET NAME = ? WHERE Id = ?;"
+	updateQuote        = "UPDATE Quotes SET BookId = ?, Quote = ?, Page = ? WHERE Id = ?;"
+	updateLanguage     = "UPDATE Languages SET Language = ? WHERE Id = ?;"
+	updateDelivery     = "UPDATE Deliveries SET QuoteId = ?, Recipient = ?, DeliveryDate = ? WHERE Id = ?;"
+	updateReview       = "UPDATE Reviews SET QuoteId = ?, EaseFactor = ?, Interval = ?, Repetitions = ?, DueDate = ? WHERE Id = ?;"
+	updateSubscription = "UPDATE Subscriptions SET Recipient = ?, Schedule = ?, Count = ?, Strategy = ?, TopicId = ?, AuthorId = ?, LanguageId = ?, BookId = ?, TimeZone = ?, SendTime = ?, QuietStart = ?, QuietEnd = ?, Quiz = ?, BookQueue = ? WHERE Id = ?;"
+	updateMessage      = "UPDATE Outbox SET Recipient = ?, Subject = ?, Body = ?, QuoteIds = ?, Status = ?, Attempts = ?, NextAttempt = ?, LastError = ?, CreatedDate = ?, Text = ?, SubscriptionId = ? WHERE Id = ?;"
+	updateFeedback     = "UPDATE Feedback SET QuoteId = ?, Recipient = ?, Kind = ?, Until = ?, FeedbackDate = ? WHERE Id = ?;"
+	lastSent           = "UPDATE Subscriptions SET LastSent = ? WHERE Id = ?;"
+	progress           = "UPDATE Subscriptions SET BookId = ?, BookQueue = ?, Cursor = ?, CursorPage = ? WHERE Id = ?;"
+)
+
+// the entries depending on a deleted entry are deleted by the triggers
+const (
+	deleteBook         = "DELETE FROM Books WHERE Id = ?;"
+	deleteTopic        = "DELETE FROM Topics WHERE Id = ?;"
+	deleteAuthor       = "DELETE FROM Authors WHERE Id = ?;"
+	deleteQuote        = "DELETE FROM Quotes WHERE Id = ?;"
+	deleteLanguage     = "DELETE FROM Languages WHERE Id = ?;"
+	deleteDelivery     = "DELETE FROM Deliveries WHERE Id = ?;"
+	deleteReview       = "DELETE FROM Reviews WHERE Id = ?;"
+	deleteSubscription = "DELETE FROM Subscriptions WHERE Id = ?;"
+	deleteMessage      = "DELETE FROM Outbox WHERE Id = ?;"
+	deleteFeedback     = "DELETE FROM Feedback WHERE Id = ?;"
 )
 
+// number of entries depending on an entry, which are deleted with it
 const (
-	insertBook     = "INSERT INTO Books (AuthorId, TopicId, ISBN, Title, LanguageId, ReleaseDate) VALUES (?, ?, ?, ?, ?, ?);"
-	insertTopic    = "INSERT INTO Topics (Topic) VALUES (?);"
-	insertAuthor   = "INSERT INTO Authors (Name) VALUES (?);"
-	insertQuote    = "INSERT INTO Quotes (BookId, Quote, Page) VALUES (?, ?, ?);"
-	insertLanguage = "INSERT INTO Languages (Language) VALUES (?);"
+	bookDependents = `(SELECT COUNT(*) FROM Quotes WHERE BookId = ?1) +
+(SELECT COUNT(*) FROM Subscriptions WHERE BookId = ?1)`
+	topicDependents = `(SELECT COUNT(*) FROM Books WHERE TopicId = ?1) +
+(SELECT COUNT(*) FROM Subscriptions WHERE TopicId = ?1)`
+	authorDependents = `(SELECT COUNT(*) FROM Books WHERE AuthorId = ?1) +
+(SELECT COUNT(*) FROM Subscriptions WHERE AuthorId = ?1)`
+	languageDependents = `(SELECT COUNT(*) FROM Books WHERE LanguageId = ?1) +
+(SELECT COUNT(*) FROM Subscriptions WHERE LanguageId = ?1)`
 )
 
+// entries depending on an entry, which are deleted with it
 const (
-	updateBook     = "UPDATE Books SET AuthorId = ?, TopicId = ?, ISBN = ?, Title = ?, LanguageId = ?, ReleaseDate = ? WHERE Id = ?;"
-	updateTopic    = "UPDATE Topics SET Topic = ? WHERE Id = ?;"
-	updateAuthor   = "UPDATE Authors SET NAME = ? WHERE Id = ?;"
-	updateQuote    = "UPDATE Quotes SET BookId = ?, Quote = ?, Page = ? WHERE Id = ?;"
-	updateLanguage = "UPDATE Languages SET Language = ? WHERE Id = ?;"
+	dependentsOfBook     = "SELECT " + bookDependents + ";"
+	dependentsOfTopic    = "SELECT " + topicDependents + ";"
+	dependentsOfAuthor   = "SELECT " + authorDependents + ";"
+	dependentsOfLanguage = "SELECT " + languageDependents + ";"
+)
+
+// delete an entry only if no entries depend on it, checking its dependents
+// in the same statement so that none can be added in between
+const (
+	restrictedDeleteBook     = "DELETE FROM Books WHERE Id = ?1 AND " + bookDependents + " = 0;"
+	restrictedDeleteTopic    = "DELETE FROM Topics WHERE Id = ?1 AND " + topicDependents + " = 0;"
+	restrictedDeleteAuthor   = "DELETE FROM Authors WHERE Id = ?1 AND " + authorDependents + " = 0;"
+	restrictedDeleteLanguage = "DELETE FROM Languages WHERE Id = ?1 AND " + languageDependents + " = 0;"
 )
 
 // related entries
@@ -467,143 +1113,409 @@ JOIN Authors ON Books.AuthorId = Authors.Id
 JOIN Topics ON Books.TopicId = Topics.Id
 JOIN Languages ON Books.LanguageId = Languages.Id
 WHERE Quotes.Quote LIKE ?;`
+	searchDeliveries    = `SELECT * FROM Deliveries WHERE Recipient LIKE ? ORDER BY DeliveryDate;`
+	searchSubscriptions = `SELECT * FROM Subscriptions WHERE Recipient LIKE ?;`
+	messagesOfStatus    = `SELECT * FROM Outbox WHERE Status = ? ORDER BY CreatedDate;`
+	pendingMessages     = `SELECT * FROM Outbox
+WHERE Status = 'pending' AND NextAttempt <= ?
+ORDER BY NextAttempt;`
+	pendingMessagesOf = `SELECT * FROM Outbox
+WHERE Status = 'pending' AND SubscriptionId = ?
+ORDER BY Id;`
+	feedbackOf = `SELECT * FROM Feedback WHERE Recipient = ? ORDER BY FeedbackDate;`
 )
 
-// Prepare the queries used for the tables created by `Init'.
-func (db *Database) Prepare() (err error) {
-	// select statements
-	db.selectTopicsStmt, err = db.connection.Prepare(selectTopics)
+// aggregations
+const (
+	deliveryCounts = `SELECT QuoteId, COUNT(*) FROM Deliveries
+WHERE Recipient = ?
+GROUP BY QuoteId;`
+	// quotes which have never been reviewed are due as well, but come after
+	// the overdue ones
+	dueQuotes = `SELECT Quotes.*, Books.*, Authors.*, Topics.*, Languages.* FROM Quotes
+JOIN Books ON Quotes.BookId = Books.Id
+JOIN Authors ON Books.AuthorId = Authors.Id
+JOIN Topics ON Books.TopicId = Topics.Id
+JOIN Languages ON Books.LanguageId = Languages.Id
+LEFT JOIN Reviews ON Reviews.QuoteId = Quotes.Id
+WHERE Reviews.DueDate IS NULL OR Reviews.DueDate <= ?
+ORDER BY Reviews.DueDate IS NULL, Reviews.DueDate, Quotes.Id;`
+	// the record dates are compared as dates, as they are stored as dates
+	// by default
+	recordedQuotes = `SELECT * FROM Quotes
+JOIN Books ON Quotes.BookId = Books.Id
+JOIN Authors ON Books.AuthorId = Authors.Id
+JOIN Topics ON Books.TopicId = Topics.Id
+JOIN Languages ON Books.LanguageId = Languages.Id
+WHERE date(Quotes.RecordDate) >= ? AND date(Quotes.RecordDate) < ?
+ORDER BY Books.Title, Quotes.Page;`
+	quotesPerTopic = `SELECT Topics.Id, Topics.Topic, COUNT(*) FROM Quotes
+JOIN Books ON Quotes.BookId = Books.Id
+JOIN Topics ON Books.TopicId = Topics.Id
+GROUP BY Topics.Id
+ORDER BY COUNT(*) DESC, Topics.Topic;`
+	quotesPerAuthor = `SELECT Authors.Id, Authors.Name, COUNT(*) FROM Quotes
+JOIN Books ON Quotes.BookId = Books.Id
+JOIN Authors ON Books.AuthorId = Authors.Id
+GROUP BY Authors.Id
+ORDER BY COUNT(*) DESC, Authors.Name;`
+	favoriteQuotes = `SELECT Quotes.Id, Quotes.Quote, COUNT(*) FROM Feedback
+JOIN Quotes ON Feedback.QuoteId = Quotes.Id
+WHERE Feedback.Kind = 'favorite'
+GROUP BY Quotes.Id
+ORDER BY COUNT(*) DESC, Quotes.Id
+LIMIT ?;`
+	collectionSize = `SELECT
+(SELECT COUNT(*) FROM Quotes),
+(SELECT COUNT(*) FROM Books),
+(SELECT COUNT(*) FROM Authors),
+(SELECT COUNT(*) FROM Topics),
+(SELECT COUNT(*) FROM Languages);`
+)
+
+// Prepare the queries used for the tables created by the migrations.
+func (db *Database) Prepare() (err error) {
+	// select statements
+	db.selectTopicsStmt, err = db.connection.Prepare(selectTopics)
+	if err != nil {
+		return
+	}
+	db.selectAuthorsStmt, err = db.connection.Prepare(selectAuthors)
+	if err != nil {
+		return
+	}
+	db.selectLanguagesStmt, err = db.connection.Prepare(selectLanguages)
+	if err != nil {
+		return
+	}
+	db.selectBooksStmt, err = db.connection.Prepare(selectBooks)
+	if err != nil {
+		return
+	}
+	db.selectQuotesStmt, err = db.connection.Prepare(selectQuotes)
+	if err != nil {
+		return
+	}
+	db.selectDeliveriesStmt, err = db.connection.Prepare(selectDeliveries)
+	if err != nil {
+		return
+	}
+	db.selectSubscriptionsStmt, err = db.connection.Prepare(selectSubscriptions)
+	if err != nil {
+		return
+	}
+	db.selectMessagesStmt, err = db.connection.Prepare(selectMessages)
+	if err != nil {
+		return
+	}
+	db.selectFeedbackStmt, err = db.connection.Prepare(selectFeedback)
+	if err != nil {
+		return
+	}
+
+	// select by id statements
+	db.selectTopicStmt, err = db.connection.Prepare(selectTopic)
+	if err != nil {
+		return
+	}
+	db.selectAuthorStmt, err = db.connection.Prepare(selectAuthor)
+	if err != nil {
+		return
+	}
+	db.selectLanguageStmt, err = db.connection.Prepare(selectLanguage)
+	if err != nil {
+		return
+	}
+	db.selectBookStmt, err = db.connection.Prepare(selectBook)
+	if err != nil {
+		return
+	}
+	db.selectQuoteStmt, err = db.connection.Prepare(selectQuote)
+	if err != nil {
+		return
+	}
+	db.selectReviewStmt, err = db.connection.Prepare(selectReview)
+	if err != nil {
+		return
+	}
+	db.selectDeliveryStmt, err = db.connection.Prepare(selectDelivery)
+	if err != nil {
+		return
+	}
+	db.selectSubscriptionStmt, err = db.connection.Prepare(selectSubscription)
+	if err != nil {
+		return
+	}
+	db.selectMessageStmt, err = db.connection.Prepare(selectMessage)
+	if err != nil {
+		return
+	}
+
+	// insert statements
+	db.insertTopicStmt, err = db.connection.Prepare(insertTopic)
+	if err != nil {
+		return
+	}
+	db.insertAuthorStmt, err = db.connection.Prepare(insertAuthor)
+	if err != nil {
+		return
+	}
+	db.insertLanguageStmt, err = db.connection.Prepare(insertLanguage)
+	if err != nil {
+		return
+	}
+	db.insertBookStmt, err = db.connection.Prepare(insertBook)
+	if err != nil {
+		return
+	}
+	db.insertQuoteStmt, err = db.connection.Prepare(insertQuote)
+	if err != nil {
+		return
+	}
+	db.insertDeliveryStmt, err = db.connection.Prepare(insertDelivery)
+	if err != nil {
+		return
+	}
+	db.insertReviewStmt, err = db.connection.Prepare(insertReview)
+	if err != nil {
+		return
+	}
+	db.insertSubscriptionStmt, err = db.connection.Prepare(insertSubscription)
+	if err != nil {
+		return
+	}
+	db.insertMessageStmt, err = db.connection.Prepare(insertMessage)
+	if err != nil {
+		return
+	}
+	db.insertFeedbackStmt, err = db.connection.Prepare(insertFeedback)
+	if err != nil {
+		return
+	}
+
+	// update statements
+	db.updateTopicStmt, err = db.connection.Prepare(updateTopic)
+	if err != nil {
+		return
+	}
+	db.updateAuthorStmt, err = db.connection.Prepare(updateAuthor)
+	if err != nil {
+		return
+	}
+	db.updateLanguageStmt, err = db.connection.Prepare(updateLanguage)
+	if err != nil {
+		return
+	}
+	db.updateBookStmt, err = db.connection.Prepare(updateBook)
+	if err != nil {
+		return
+	}
+	db.updateQuoteStmt, err = db.connection.Prepare(updateQuote)
+	if err != nil {
+		return
+	}
+	db.updateDeliveryStmt, err = db.connection.Prepare(updateDelivery)
+	if err != nil {
+		return
+	}
+	db.updateReviewStmt, err = db.connection.Prepare(updateReview)
+	if err != nil {
+		return
+	}
+	db.updateSubscriptionStmt, err = db.connection.Prepare(updateSubscription)
+	if err != nil {
+		return
+	}
+	db.updateMessageStmt, err = db.connection.Prepare(updateMessage)
+	if err != nil {
+		return
+	}
+	db.updateFeedbackStmt, err = db.connection.Prepare(updateFeedback)
+	if err != nil {
+		return
+	}
+	db.lastSentStmt, err = db.connection.Prepare(lastSent)
+	if err != nil {
+		return
+	}
+	db.progressStmt, err = db.connection.Prepare(progress)
+	if err != nil {
+		return
+	}
+
+	// related entries statements
+	db.relatedBooksOfTopicStmt, err = db.connection.Prepare(relatedBooksOfTopic)
+	if err != nil {
+		return
+	}
+	db.relatedQuotesOfTopicStmt, err = db.connection.Prepare(relatedQuotesOfTopic)
+	if err != nil {
+		return
+	}
+	db.relatedBooksOfAuthorStmt, err = db.connection.Prepare(relatedBooksOfAuthor)
+	if err != nil {
+		return
+	}
+	db.relatedQuotesOfAuthorStmt, err = db.connection.Prepare(relatedQuotesOfAuthor)
+	if err != nil {
+		return
+	}
+	db.relatedBooksOfLanguageStmt, err = db.connection.Prepare(relatedBooksOfLanguage)
+	if err != nil {
+		return
+	}
+	db.relatedQuotesOfLanguageStmt, err = db.connection.Prepare(relatedQuotesOfLanguage)
+	if err != nil {
+		return
+	}
+	db.relatedQuotesOfBookStmt, err = db.connection.Prepare(relatedQuotesOfBook)
+
+	// delete statements
+	db.deleteBookStmt, err = db.connection.Prepare(deleteBook)
 	if err != nil {
 		return
 	}
-	db.selectAuthorsStmt, err = db.connection.Prepare(selectAuthors)
+	db.deleteTopicStmt, err = db.connection.Prepare(deleteTopic)
 	if err != nil {
 		return
 	}
-	db.selectLanguagesStmt, err = db.connection.Prepare(selectLanguages)
+	db.deleteAuthorStmt, err = db.connection.Prepare(deleteAuthor)
 	if err != nil {
 		return
 	}
-	db.selectBooksStmt, err = db.connection.Prepare(selectBooks)
+	db.deleteQuoteStmt, err = db.connection.Prepare(deleteQuote)
 	if err != nil {
 		return
 	}
-	db.selectQuotesStmt, err = db.connection.Prepare(selectQuotes)
+	db.deleteLanguageStmt, err = db.connection.Prepare(deleteLanguage)
 	if err != nil {
 		return
 	}
-
-	// select by id statements
-	db.selectTopicStmt, err = db.connection.Prepare(selectTopic)
+	db.deleteDeliveryStmt, err = db.connection.Prepare(deleteDelivery)
 	if err != nil {
 		return
 	}
-	db.selectAuthorStmt, err = db.connection.Prepare(selectAuthor)
+	db.deleteReviewStmt, err = db.connection.Prepare(deleteReview)
 	if err != nil {
 		return
 	}
-	db.selectLanguageStmt, err = db.connection.Prepare(selectLanguage)
+	db.deleteSubscriptionStmt, err = db.connection.Prepare(deleteSubscription)
 	if err != nil {
 		return
 	}
-	db.selectBookStmt, err = db.connection.Prepare(selectBook)
+	db.deleteMessageStmt, err = db.connection.Prepare(deleteMessage)
 	if err != nil {
 		return
 	}
-	db.selectQuoteStmt, err = db.connection.Prepare(selectQuote)
+	db.deleteFeedbackStmt, err = db.connection.Prepare(deleteFeedback)
 	if err != nil {
 		return
 	}
-
-	// insert statements
-	db.insertTopicStmt, err = db.connection.Prepare(insertTopic)
+	db.dependentsOfBookStmt, err = db.connection.Prepare(dependentsOfBook)
 	if err != nil {
 		return
 	}
-	db.insertAuthorStmt, err = db.connection.Prepare(insertAuthor)
+	db.dependentsOfTopicStmt, err = db.connection.Prepare(dependentsOfTopic)
 	if err != nil {
 		return
 	}
-	db.insertLanguageStmt, err = db.connection.Prepare(insertLanguage)
+	db.dependentsOfAuthorStmt, err = db.connection.Prepare(dependentsOfAuthor)
 	if err != nil {
 		return
 	}
-	db.insertBookStmt, err = db.connection.Prepare(insertBook)
+	db.dependentsOfLanguageStmt, err = db.connection.Prepare(dependentsOfLanguage)
 	if err != nil {
 		return
 	}
-	db.insertQuoteStmt, err = db.connection.Prepare(insertQuote)
+	db.restrictedDeleteBookStmt, err = db.connection.Prepare(restrictedDeleteBook)
+	if err != nil {
+		return
+	}
+	db.restrictedDeleteTopicStmt, err = db.connection.Prepare(restrictedDeleteTopic)
+	if err != nil {
+		return
+	}
+	db.restrictedDeleteAuthorStmt, err = db.connection.Prepare(restrictedDeleteAuthor)
+	if err != nil {
+		return
+	}
+	db.restrictedDeleteLanguageStmt, err = db.connection.Prepare(restrictedDeleteLanguage)
 	if err != nil {
 		return
 	}
 
-	// update statements
-	db.updateTopicStmt, err = db.connection.Prepare(updateTopic)
+	// searches
+	db.searchTopicsStmt, err = db.connection.Prepare(searchTopics)
 	if err != nil {
 		return
 	}
-	db.updateAuthorStmt, err = db.connection.Prepare(updateAuthor)
+	db.searchAuthorsStmt, err = db.connection.Prepare(searchAuthors)
 	if err != nil {
 		return
 	}
-	db.updateLanguageStmt, err = db.connection.Prepare(updateLanguage)
+	db.searchLanguagesStmt, err = db.connection.Prepare(searchLanguages)
 	if err != nil {
 		return
 	}
-	db.updateBookStmt, err = db.connection.Prepare(updateBook)
+	db.searchBooksStmt, err = db.connection.Prepare(searchBooks)
 	if err != nil {
 		return
 	}
-	db.updateQuoteStmt, err = db.connection.Prepare(updateQuote)
+	db.searchQuotesStmt, err = db.connection.Prepare(searchQuotes)
 	if err != nil {
 		return
 	}
-
-	// related entries statements
-	db.relatedBooksOfTopicStmt, err = db.connection.Prepare(relatedBooksOfTopic)
+	db.searchDeliveriesStmt, err = db.connection.Prepare(searchDeliveries)
 	if err != nil {
 		return
 	}
-	db.relatedQuotesOfTopicStmt, err = db.connection.Prepare(relatedQuotesOfTopic)
+	db.searchSubscriptionsStmt, err = db.connection.Prepare(searchSubscriptions)
 	if err != nil {
 		return
 	}
-	db.relatedBooksOfAuthorStmt, err = db.connection.Prepare(relatedBooksOfAuthor)
+	db.messagesOfStatusStmt, err = db.connection.Prepare(messagesOfStatus)
 	if err != nil {
 		return
 	}
-	db.relatedQuotesOfAuthorStmt, err = db.connection.Prepare(relatedQuotesOfAuthor)
+	db.pendingMessagesStmt, err = db.connection.Prepare(pendingMessages)
 	if err != nil {
 		return
 	}
-	db.relatedBooksOfLanguageStmt, err = db.connection.Prepare(relatedBooksOfLanguage)
+	db.pendingMessagesOfStmt, err = db.connection.Prepare(pendingMessagesOf)
 	if err != nil {
 		return
 	}
-	db.relatedQuotesOfLanguageStmt, err = db.connection.Prepare(relatedQuotesOfLanguage)
+	db.feedbackOfStmt, err = db.connection.Prepare(feedbackOf)
 	if err != nil {
 		return
 	}
-	db.relatedQuotesOfBookStmt, err = db.connection.Prepare(relatedQuotesOfBook)
 
-	// searches
-	db.searchTopicsStmt, err = db.connection.Prepare(searchTopics)
+	// aggregations
+	db.deliveryCountsStmt, err = db.connection.Prepare(deliveryCounts)
 	if err != nil {
 		return
 	}
-	db.searchAuthorsStmt, err = db.connection.Prepare(searchAuthors)
+	db.dueQuotesStmt, err = db.connection.Prepare(dueQuotes)
 	if err != nil {
 		return
 	}
-	db.searchLanguagesStmt, err = db.connection.Prepare(searchLanguages)
+	db.recordedQuotesStmt, err = db.connection.Prepare(recordedQuotes)
 	if err != nil {
 		return
 	}
-	db.searchBooksStmt, err = db.connection.Prepare(searchBooks)
+	db.quotesPerTopicStmt, err = db.connection.Prepare(quotesPerTopic)
 	if err != nil {
 		return
 	}
-	db.searchQuotesStmt, err = db.connection.Prepare(searchQuotes)
+	db.quotesPerAuthorStmt, err = db.connection.Prepare(quotesPerAuthor)
+	if err != nil {
+		return
+	}
+	db.favoriteQuotesStmt, err = db.connection.Prepare(favoriteQuotes)
+	if err != nil {
+		return
+	}
+	db.collectionSizeStmt, err = db.connection.Prepare(collectionSize)
 	if err != nil {
 		return
 	}
@@ -615,6 +1527,9 @@ func (db Database) GetTopic(id int) (topic Topic, err error) {
 	if res, err = db.selectTopicStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
 			topic.stmt = db.updateTopicStmt
+			topic.deleteStmt = db.deleteTopicStmt
+			topic.dependentsStmt = db.dependentsOfTopicStmt
+			topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&topic.Id, &topic.Topic)
 		}
 	}
@@ -625,7 +1540,12 @@ func (db Database) GetTopics() (topics []Topic, err error) {
 	var res *sql.Rows
 	if res, err = db.selectTopicsStmt.Query(); res != nil {
 		for res.Next() && err == nil {
-			topic := Topic{stmt: db.updateTopicStmt}
+			topic := Topic{
+				stmt:           db.updateTopicStmt,
+				deleteStmt:     db.deleteTopicStmt,
+				dependentsStmt: db.dependentsOfTopicStmt,
+				restrictStmt:   db.restrictedDeleteTopicStmt,
+			}
 			err = res.Scan(&topic.Id, &topic.Topic)
 			topics = append(topics, topic)
 		}
@@ -637,10 +1557,24 @@ func (db Database) RelatedBooksOfTopic(id int) (books []Book, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedBooksOfTopicStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			book := Book{stmt: db.updateBookStmt}
+			book := Book{
+				stmt:           db.updateBookStmt,
+				deleteStmt:     db.deleteBookStmt,
+				dependentsStmt: db.dependentsOfBookStmt,
+				restrictStmt:   db.restrictedDeleteBookStmt,
+			}
 			book.Language.stmt = db.updateLanguageStmt
+			book.Language.deleteStmt = db.deleteLanguageStmt
+			book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			book.Author.stmt = db.updateAuthorStmt
+			book.Author.deleteStmt = db.deleteAuthorStmt
+			book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			book.Topic.stmt = db.updateTopicStmt
+			book.Topic.deleteStmt = db.deleteTopicStmt
+			book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&book.Id,
 				&book.Author.Id,
 				&book.Topic.Id,
@@ -664,11 +1598,26 @@ func (db Database) RelatedQuotesOfTopic(id int) (quotes []Quote, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedQuotesOfTopicStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			quote := Quote{stmt: db.updateQuoteStmt}
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -697,7 +1646,12 @@ func (db Database) SearchTopics(search string) (topics []Topic, err error) {
 	var res *sql.Rows
 	if res, err = db.searchTopicsStmt.Query("%" + search + "%"); res != nil {
 		for res.Next() && err == nil {
-			topic := Topic{stmt: db.updateTopicStmt}
+			topic := Topic{
+				stmt:           db.updateTopicStmt,
+				deleteStmt:     db.deleteTopicStmt,
+				dependentsStmt: db.dependentsOfTopicStmt,
+				restrictStmt:   db.restrictedDeleteTopicStmt,
+			}
 			err = res.Scan(&topic.Id, &topic.Topic)
 			topics = append(topics, topic)
 		}
@@ -710,6 +1664,9 @@ func (db Database) GetAuthor(id int) (author Author, err error) {
 	if res, err = db.selectAuthorStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
 			author.stmt = db.updateAuthorStmt
+			author.deleteStmt = db.deleteAuthorStmt
+			author.dependentsStmt = db.dependentsOfAuthorStmt
+			author.restrictStmt = db.restrictedDeleteAuthorStmt
 			err = res.Scan(&author.Id, &author.Name)
 		}
 	}
@@ -720,7 +1677,12 @@ func (db Database) GetAuthors() (authors []Author, err error) {
 	var res *sql.Rows
 	if res, err = db.selectAuthorsStmt.Query(); res != nil {
 		for res.Next() && err == nil {
-			author := Author{stmt: db.updateAuthorStmt}
+			author := Author{
+				stmt:           db.updateAuthorStmt,
+				deleteStmt:     db.deleteAuthorStmt,
+				dependentsStmt: db.dependentsOfAuthorStmt,
+				restrictStmt:   db.restrictedDeleteAuthorStmt,
+			}
 			err = res.Scan(&author.Id, &author.Name)
 			authors = append(authors, author)
 		}
@@ -732,10 +1694,24 @@ func (db Database) RelatedBooksOfAuthor(id int) (books []Book, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedBooksOfAuthorStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			book := Book{stmt: db.updateBookStmt}
+			book := Book{
+				stmt:           db.updateBookStmt,
+				deleteStmt:     db.deleteBookStmt,
+				dependentsStmt: db.dependentsOfBookStmt,
+				restrictStmt:   db.restrictedDeleteBookStmt,
+			}
 			book.Language.stmt = db.updateLanguageStmt
+			book.Language.deleteStmt = db.deleteLanguageStmt
+			book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			book.Author.stmt = db.updateAuthorStmt
+			book.Author.deleteStmt = db.deleteAuthorStmt
+			book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			book.Topic.stmt = db.updateTopicStmt
+			book.Topic.deleteStmt = db.deleteTopicStmt
+			book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&book.Id,
 				&book.Author.Id,
 				&book.Topic.Id,
@@ -759,11 +1735,26 @@ func (db Database) RelatedQuotesOfAuthor(id int) (quotes []Quote, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedQuotesOfAuthorStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			quote := Quote{stmt: db.updateQuoteStmt}
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -792,7 +1783,12 @@ func (db Database) SearchAuthors(search string) (authors []Author, err error) {
 	var res *sql.Rows
 	if res, err = db.searchAuthorsStmt.Query("%" + search + "%"); res != nil {
 		for res.Next() && err == nil {
-			author := Author{stmt: db.updateAuthorStmt}
+			author := Author{
+				stmt:           db.updateAuthorStmt,
+				deleteStmt:     db.deleteAuthorStmt,
+				dependentsStmt: db.dependentsOfAuthorStmt,
+				restrictStmt:   db.restrictedDeleteAuthorStmt,
+			}
 			err = res.Scan(&author.Id, &author.Name)
 			authors = append(authors, author)
 		}
@@ -805,6 +1801,9 @@ func (db Database) GetLanguage(id int) (language Language, err error) {
 	if res, err = db.selectLanguageStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
 			language.stmt = db.updateLanguageStmt
+			language.deleteStmt = db.deleteLanguageStmt
+			language.dependentsStmt = db.dependentsOfLanguageStmt
+			language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&language.Id, &language.Language)
 		}
 	}
@@ -815,7 +1814,12 @@ func (db Database) GetLanguages() (languages []Language, err error) {
 	var res *sql.Rows
 	if res, err = db.selectLanguagesStmt.Query(); res != nil {
 		for res.Next() && err == nil {
-			language := Language{stmt: db.updateLanguageStmt}
+			language := Language{
+				stmt:           db.updateLanguageStmt,
+				deleteStmt:     db.deleteLanguageStmt,
+				dependentsStmt: db.dependentsOfLanguageStmt,
+				restrictStmt:   db.restrictedDeleteLanguageStmt,
+			}
 			err = res.Scan(&language.Id, &language.Language)
 			languages = append(languages, language)
 		}
@@ -827,10 +1831,24 @@ func (db Database) RelatedBooksOfLanguage(id int) (books []Book, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedBooksOfLanguageStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			book := Book{stmt: db.updateBookStmt}
+			book := Book{
+				stmt:           db.updateBookStmt,
+				deleteStmt:     db.deleteBookStmt,
+				dependentsStmt: db.dependentsOfBookStmt,
+				restrictStmt:   db.restrictedDeleteBookStmt,
+			}
 			book.Language.stmt = db.updateLanguageStmt
+			book.Language.deleteStmt = db.deleteLanguageStmt
+			book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			book.Author.stmt = db.updateAuthorStmt
+			book.Author.deleteStmt = db.deleteAuthorStmt
+			book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			book.Topic.stmt = db.updateTopicStmt
+			book.Topic.deleteStmt = db.deleteTopicStmt
+			book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&book.Id,
 				&book.Author.Id,
 				&book.Topic.Id,
@@ -854,11 +1872,26 @@ func (db Database) RelatedQuotesOfLanguage(id int) (quotes []Quote, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedQuotesOfLanguageStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			quote := Quote{stmt: db.updateQuoteStmt}
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -887,7 +1920,12 @@ func (db Database) SearchLanguages(search string) (languages []Language, err err
 	var res *sql.Rows
 	if res, err = db.searchLanguagesStmt.Query("%" + search + "%"); res != nil {
 		for res.Next() && err == nil {
-			language := Language{stmt: db.updateLanguageStmt}
+			language := Language{
+				stmt:           db.updateLanguageStmt,
+				deleteStmt:     db.deleteLanguageStmt,
+				dependentsStmt: db.dependentsOfLanguageStmt,
+				restrictStmt:   db.restrictedDeleteLanguageStmt,
+			}
 			err = res.Scan(&language.Id, &language.Language)
 			languages = append(languages, language)
 		}
@@ -900,9 +1938,21 @@ func (db Database) GetBook(id int) (book Book, err error) {
 	if res, err = db.selectBookStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
 			book.stmt = db.updateBookStmt
+			book.deleteStmt = db.deleteBookStmt
+			book.dependentsStmt = db.dependentsOfBookStmt
+			book.restrictStmt = db.restrictedDeleteBookStmt
 			book.Language.stmt = db.updateLanguageStmt
+			book.Language.deleteStmt = db.deleteLanguageStmt
+			book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			book.Author.stmt = db.updateAuthorStmt
+			book.Author.deleteStmt = db.deleteAuthorStmt
+			book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			book.Topic.stmt = db.updateTopicStmt
+			book.Topic.deleteStmt = db.deleteTopicStmt
+			book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&book.Id,
 				&book.Author.Id,
 				&book.Topic.Id,
@@ -925,10 +1975,24 @@ func (db Database) GetBooks() (books []Book, err error) {
 	var res *sql.Rows
 	if res, err = db.selectBooksStmt.Query(); res != nil {
 		for res.Next() && err == nil {
-			book := Book{stmt: db.updateBookStmt}
+			book := Book{
+				stmt:           db.updateBookStmt,
+				deleteStmt:     db.deleteBookStmt,
+				dependentsStmt: db.dependentsOfBookStmt,
+				restrictStmt:   db.restrictedDeleteBookStmt,
+			}
 			book.Language.stmt = db.updateLanguageStmt
+			book.Language.deleteStmt = db.deleteLanguageStmt
+			book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			book.Author.stmt = db.updateAuthorStmt
+			book.Author.deleteStmt = db.deleteAuthorStmt
+			book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			book.Topic.stmt = db.updateTopicStmt
+			book.Topic.deleteStmt = db.deleteTopicStmt
+			book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&book.Id,
 				&book.Author.Id,
 				&book.Topic.Id,
@@ -952,11 +2016,26 @@ func (db Database) RelatedQuotesOfBook(id int) (quotes []Quote, err error) {
 	var res *sql.Rows
 	if res, err = db.relatedQuotesOfBookStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
-			quote := Quote{stmt: db.updateQuoteStmt}
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -985,10 +2064,24 @@ func (db Database) SearchBooks(search string) (books []Book, err error) {
 	var res *sql.Rows
 	if res, err = db.searchBooksStmt.Query("%"+search+"%", "%"+search+"%"); res != nil {
 		for res.Next() && err == nil {
-			book := Book{stmt: db.updateBookStmt}
+			book := Book{
+				stmt:           db.updateBookStmt,
+				deleteStmt:     db.deleteBookStmt,
+				dependentsStmt: db.dependentsOfBookStmt,
+				restrictStmt:   db.restrictedDeleteBookStmt,
+			}
 			book.Language.stmt = db.updateLanguageStmt
+			book.Language.deleteStmt = db.deleteLanguageStmt
+			book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			book.Author.stmt = db.updateAuthorStmt
+			book.Author.deleteStmt = db.deleteAuthorStmt
+			book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			book.Topic.stmt = db.updateTopicStmt
+			book.Topic.deleteStmt = db.deleteTopicStmt
+			book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			err = res.Scan(&book.Id,
 				&book.Author.Id,
 				&book.Topic.Id,
@@ -1013,10 +2106,23 @@ func (db Database) GetQuote(id int) (quote Quote, err error) {
 	if res, err = db.selectQuoteStmt.Query(id); res != nil {
 		for res.Next() && err == nil {
 			quote.stmt = db.updateQuoteStmt
+			quote.deleteStmt = db.deleteQuoteStmt
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -1044,11 +2150,26 @@ func (db Database) GetQuotes() (quotes []Quote, err error) {
 	var res *sql.Rows
 	if res, err = db.selectQuotesStmt.Query(); res != nil {
 		for res.Next() && err == nil {
-			quote := Quote{stmt: db.updateQuoteStmt}
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -1077,11 +2198,211 @@ func (db Database) SearchQuotes(search string) (quotes []Quote, err error) {
 	var res *sql.Rows
 	if res, err = db.searchQuotesStmt.Query("%" + search + "%"); res != nil {
 		for res.Next() && err == nil {
-			quote := Quote{stmt: db.updateQuoteStmt}
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
+			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
+			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
+			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
+			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
+			err = res.Scan(&quote.Id,
+				&quote.Book.Id,
+				&quote.Quote,
+				&quote.Page,
+				&quote.RecordDate,
+				&quote.Book.Id,
+				&quote.Book.Author.Id,
+				&quote.Book.Topic.Id,
+				&quote.Book.ISBN,
+				&quote.Book.Title,
+				&quote.Book.Language.Id,
+				&quote.Book.ReleaseDate,
+				&quote.Book.Author.Id,
+				&quote.Book.Author.Name,
+				&quote.Book.Topic.Id,
+				&quote.Book.Topic.Topic,
+				&quote.Book.Language.Id,
+				&quote.Book.Language.Language)
+			quotes = append(quotes, quote)
+		}
+	}
+	return
+}
+
+func (db Database) GetDelivery(id int) (delivery Delivery, err error) {
+	var res *sql.Rows
+	if res, err = db.selectDeliveryStmt.Query(id); res != nil {
+		for res.Next() && err == nil {
+			delivery.stmt = db.updateDeliveryStmt
+			delivery.deleteStmt = db.deleteDeliveryStmt
+			err = res.Scan(&delivery.Id,
+				&delivery.QuoteId,
+				&delivery.Recipient,
+				&delivery.DeliveryDate)
+		}
+	}
+	return
+}
+
+func (db Database) GetDeliveries() (deliveries []Delivery, err error) {
+	var res *sql.Rows
+	if res, err = db.selectDeliveriesStmt.Query(); res != nil {
+		for res.Next() && err == nil {
+			delivery := Delivery{
+				stmt:       db.updateDeliveryStmt,
+				deleteStmt: db.deleteDeliveryStmt,
+			}
+			err = res.Scan(&delivery.Id,
+				&delivery.QuoteId,
+				&delivery.Recipient,
+				&delivery.DeliveryDate)
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return
+}
+
+func (db Database) SearchDeliveries(search string) (deliveries []Delivery, err error) {
+	var res *sql.Rows
+	if res, err = db.searchDeliveriesStmt.Query("%" + search + "%"); res != nil {
+		for res.Next() && err == nil {
+			delivery := Delivery{
+				stmt:       db.updateDeliveryStmt,
+				deleteStmt: db.deleteDeliveryStmt,
+			}
+			err = res.Scan(&delivery.Id,
+				&delivery.QuoteId,
+				&delivery.Recipient,
+				&delivery.DeliveryDate)
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return
+}
+
+// DeliveryCounts returns how often each quote has been delivered to
+// `recipient`, quotes which have never been delivered are not contained
+func (db Database) DeliveryCounts(recipient string) (counts map[int]int, err error) {
+	counts = make(map[int]int)
+	var res *sql.Rows
+	if res, err = db.deliveryCountsStmt.Query(recipient); res != nil {
+		for res.Next() && err == nil {
+			var quoteId, count int
+			err = res.Scan(&quoteId, &count)
+			counts[quoteId] = count
+		}
+	}
+	return
+}
+
+// GetReview returns the Review of the quote with `quoteId`, which is
+// DefaultReview if the quote has not been reviewed yet
+func (db Database) GetReview(quoteId int) (review Review, err error) {
+	var res *sql.Rows
+	if res, err = db.selectReviewStmt.Query(quoteId); res != nil {
+		for res.Next() && err == nil {
+			review.stmt = db.updateReviewStmt
+			review.deleteStmt = db.deleteReviewStmt
+			err = res.Scan(&review.Id,
+				&review.QuoteId,
+				&review.EaseFactor,
+				&review.Interval,
+				&review.Repetitions,
+				&review.DueDate)
+		}
+	}
+	return
+}
+
+// DueQuotes returns the quotes which are due for a review at `date`, starting
+// with the quotes which are overdue the longest
+func (db Database) DueQuotes(date time.Time) (quotes []Quote, err error) {
+	var res *sql.Rows
+	if res, err = db.dueQuotesStmt.Query(date.UTC().Truncate(time.Second)); res != nil {
+		for res.Next() && err == nil {
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
+			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
+			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
+			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
+			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
+			err = res.Scan(&quote.Id,
+				&quote.Book.Id,
+				&quote.Quote,
+				&quote.Page,
+				&quote.RecordDate,
+				&quote.Book.Id,
+				&quote.Book.Author.Id,
+				&quote.Book.Topic.Id,
+				&quote.Book.ISBN,
+				&quote.Book.Title,
+				&quote.Book.Language.Id,
+				&quote.Book.ReleaseDate,
+				&quote.Book.Author.Id,
+				&quote.Book.Author.Name,
+				&quote.Book.Topic.Id,
+				&quote.Book.Topic.Topic,
+				&quote.Book.Language.Id,
+				&quote.Book.Language.Language)
+			quotes = append(quotes, quote)
+		}
+	}
+	return
+}
+
+// RecordedQuotes returns the quotes recorded on the days from `from` up to
+// but excluding `to`, ordered by book and page
+func (db Database) RecordedQuotes(from, to time.Time) (quotes []Quote, err error) {
+	var res *sql.Rows
+	if res, err = db.recordedQuotesStmt.Query(from.Format("2006-01-02"), to.Format("2006-01-02")); res != nil {
+		for res.Next() && err == nil {
+			quote := Quote{
+				stmt:       db.updateQuoteStmt,
+				deleteStmt: db.deleteQuoteStmt,
+			}
 			quote.Book.stmt = db.updateBookStmt
+			quote.Book.deleteStmt = db.deleteBookStmt
+			quote.Book.dependentsStmt = db.dependentsOfBookStmt
+			quote.Book.restrictStmt = db.restrictedDeleteBookStmt
 			quote.Book.Author.stmt = db.updateAuthorStmt
+			quote.Book.Author.deleteStmt = db.deleteAuthorStmt
+			quote.Book.Author.dependentsStmt = db.dependentsOfAuthorStmt
+			quote.Book.Author.restrictStmt = db.restrictedDeleteAuthorStmt
 			quote.Book.Topic.stmt = db.updateTopicStmt
+			quote.Book.Topic.deleteStmt = db.deleteTopicStmt
+			quote.Book.Topic.dependentsStmt = db.dependentsOfTopicStmt
+			quote.Book.Topic.restrictStmt = db.restrictedDeleteTopicStmt
 			quote.Book.Language.stmt = db.updateLanguageStmt
+			quote.Book.Language.deleteStmt = db.deleteLanguageStmt
+			quote.Book.Language.dependentsStmt = db.dependentsOfLanguageStmt
+			quote.Book.Language.restrictStmt = db.restrictedDeleteLanguageStmt
 			err = res.Scan(&quote.Id,
 				&quote.Book.Id,
 				&quote.Quote,
@@ -1105,3 +2426,325 @@ func (db Database) SearchQuotes(search string) (quotes []Quote, err error) {
 	}
 	return
 }
+
+// tallies scans the result of a query selecting an id, name and count
+func tallies(stmt *sql.Stmt, args ...interface{}) (res []Tally, err error) {
+	var rows *sql.Rows
+	if rows, err = stmt.Query(args...); rows != nil {
+		for rows.Next() && err == nil {
+			var tally Tally
+			err = rows.Scan(&tally.Id, &tally.Name, &tally.Count)
+			res = append(res, tally)
+		}
+	}
+	return
+}
+
+// QuotesPerTopic returns the number of quotes of each topic, starting with
+// the topic with the most quotes
+func (db Database) QuotesPerTopic() ([]Tally, error) {
+	return tallies(db.quotesPerTopicStmt)
+}
+
+// QuotesPerAuthor returns the number of quotes of each author, starting with
+// the author with the most quotes
+func (db Database) QuotesPerAuthor() ([]Tally, error) {
+	return tallies(db.quotesPerAuthorStmt)
+}
+
+// FavoriteQuotes returns the `limit` quotes which have been marked as
+// favorite the most, with the quote as Name
+func (db Database) FavoriteQuotes(limit int) ([]Tally, error) {
+	return tallies(db.favoriteQuotesStmt, limit)
+}
+
+// CollectionSize returns the number of entries of the collection
+func (db Database) CollectionSize() (size Size, err error) {
+	err = db.collectionSizeStmt.QueryRow().Scan(&size.Quotes, &size.Books,
+		&size.Authors, &size.Topics, &size.Languages)
+	return
+}
+
+func (db Database) GetSubscription(id int) (subscription Subscription, err error) {
+	var res *sql.Rows
+	if res, err = db.selectSubscriptionStmt.Query(id); res != nil {
+		for res.Next() && err == nil {
+			subscription.stmt = db.updateSubscriptionStmt
+			subscription.deleteStmt = db.deleteSubscriptionStmt
+			err = res.Scan(&subscription.Id,
+				&subscription.Recipient,
+				&subscription.Schedule,
+				&subscription.Count,
+				&subscription.Strategy,
+				&subscription.TopicId,
+				&subscription.AuthorId,
+				&subscription.LanguageId,
+				&subscription.BookId,
+				&subscription.TimeZone,
+				&subscription.SendTime,
+				&subscription.QuietStart,
+				&subscription.QuietEnd,
+				&subscription.LastSent,
+				&subscription.Quiz,
+				&subscription.BookQueue,
+				&subscription.Cursor,
+				&subscription.CursorPage)
+		}
+	}
+	return
+}
+
+func (db Database) GetSubscriptions() (subscriptions []Subscription, err error) {
+	var res *sql.Rows
+	if res, err = db.selectSubscriptionsStmt.Query(); res != nil {
+		for res.Next() && err == nil {
+			subscription := Subscription{
+				stmt:       db.updateSubscriptionStmt,
+				deleteStmt: db.deleteSubscriptionStmt,
+			}
+			err = res.Scan(&subscription.Id,
+				&subscription.Recipient,
+				&subscription.Schedule,
+				&subscription.Count,
+				&subscription.Strategy,
+				&subscription.TopicId,
+				&subscription.AuthorId,
+				&subscription.LanguageId,
+				&subscription.BookId,
+				&subscription.TimeZone,
+				&subscription.SendTime,
+				&subscription.QuietStart,
+				&subscription.QuietEnd,
+				&subscription.LastSent,
+				&subscription.Quiz,
+				&subscription.BookQueue,
+				&subscription.Cursor,
+				&subscription.CursorPage)
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return
+}
+
+// SubscriptionSent records that the last reminder of the subscription with
+// `id` was sent at `date`
+func (db Database) SubscriptionSent(id int, date time.Time) (err error) {
+	_, err = db.lastSentStmt.Exec(date.UTC().Truncate(time.Second), id)
+	return
+}
+
+// SubscriptionProgress records the progress of the series of the
+// subscription with `id`, which has sent the quote `cursor` on the page
+// `cursorPage` of the book `bookId` last and continues with the books
+// `bookQueue` afterwards
+func (db Database) SubscriptionProgress(id, bookId int, bookQueue []int, cursor, cursorPage int) (err error) {
+	_, err = db.progressStmt.Exec(bookId, joinIds(bookQueue), cursor, cursorPage, id)
+	return
+}
+
+func (db Database) SearchSubscriptions(search string) (subscriptions []Subscription, err error) {
+	var res *sql.Rows
+	if res, err = db.searchSubscriptionsStmt.Query("%" + search + "%"); res != nil {
+		for res.Next() && err == nil {
+			subscription := Subscription{
+				stmt:       db.updateSubscriptionStmt,
+				deleteStmt: db.deleteSubscriptionStmt,
+			}
+			err = res.Scan(&subscription.Id,
+				&subscription.Recipient,
+				&subscription.Schedule,
+				&subscription.Count,
+				&subscription.Strategy,
+				&subscription.TopicId,
+				&subscription.AuthorId,
+				&subscription.LanguageId,
+				&subscription.BookId,
+				&subscription.TimeZone,
+				&subscription.SendTime,
+				&subscription.QuietStart,
+				&subscription.QuietEnd,
+				&subscription.LastSent,
+				&subscription.Quiz,
+				&subscription.BookQueue,
+				&subscription.Cursor,
+				&subscription.CursorPage)
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return
+}
+
+func (db Database) GetMessage(id int) (message Message, err error) {
+	var res *sql.Rows
+	if res, err = db.selectMessageStmt.Query(id); res != nil {
+		for res.Next() && err == nil {
+			message.stmt = db.updateMessageStmt
+			message.deleteStmt = db.deleteMessageStmt
+			err = res.Scan(&message.Id,
+				&message.Recipient,
+				&message.Subject,
+				&message.Body,
+				&message.QuoteIds,
+				&message.Status,
+				&message.Attempts,
+				&message.NextAttempt,
+				&message.LastError,
+				&message.CreatedDate,
+				&message.Text,
+				&message.SubscriptionId)
+		}
+	}
+	return
+}
+
+func (db Database) GetMessages() (messages []Message, err error) {
+	var res *sql.Rows
+	if res, err = db.selectMessagesStmt.Query(); res != nil {
+		for res.Next() && err == nil {
+			message := Message{
+				stmt:       db.updateMessageStmt,
+				deleteStmt: db.deleteMessageStmt,
+			}
+			err = res.Scan(&message.Id,
+				&message.Recipient,
+				&message.Subject,
+				&message.Body,
+				&message.QuoteIds,
+				&message.Status,
+				&message.Attempts,
+				&message.NextAttempt,
+				&message.LastError,
+				&message.CreatedDate,
+				&message.Text,
+				&message.SubscriptionId)
+			messages = append(messages, message)
+		}
+	}
+	return
+}
+
+// MessagesOfStatus returns the messages in the outbox with `status` (one of
+// MessagePending, MessageSent or MessageFailed)
+func (db Database) MessagesOfStatus(status string) (messages []Message, err error) {
+	var res *sql.Rows
+	if res, err = db.messagesOfStatusStmt.Query(status); res != nil {
+		for res.Next() && err == nil {
+			message := Message{
+				stmt:       db.updateMessageStmt,
+				deleteStmt: db.deleteMessageStmt,
+			}
+			err = res.Scan(&message.Id,
+				&message.Recipient,
+				&message.Subject,
+				&message.Body,
+				&message.QuoteIds,
+				&message.Status,
+				&message.Attempts,
+				&message.NextAttempt,
+				&message.LastError,
+				&message.CreatedDate,
+				&message.Text,
+				&message.SubscriptionId)
+			messages = append(messages, message)
+		}
+	}
+	return
+}
+
+// PendingMessages returns the pending messages in the outbox, which should be
+// attempted to be delivered at `date`
+func (db Database) PendingMessages(date time.Time) (messages []Message, err error) {
+	var res *sql.Rows
+	if res, err = db.pendingMessagesStmt.Query(date.UTC().Truncate(time.Second)); res != nil {
+		for res.Next() && err == nil {
+			message := Message{
+				stmt:       db.updateMessageStmt,
+				deleteStmt: db.deleteMessageStmt,
+			}
+			err = res.Scan(&message.Id,
+				&message.Recipient,
+				&message.Subject,
+				&message.Body,
+				&message.QuoteIds,
+				&message.Status,
+				&message.Attempts,
+				&message.NextAttempt,
+				&message.LastError,
+				&message.CreatedDate,
+				&message.Text,
+				&message.SubscriptionId)
+			messages = append(messages, message)
+		}
+	}
+	return
+}
+
+// PendingMessagesOf returns the pending messages in the outbox, which were
+// queued for the subscription with the id `subscriptionId`, in the order
+// they were queued
+func (db Database) PendingMessagesOf(subscriptionId int) (messages []Message, err error) {
+	var res *sql.Rows
+	if res, err = db.pendingMessagesOfStmt.Query(subscriptionId); res != nil {
+		for res.Next() && err == nil {
+			message := Message{
+				stmt:       db.updateMessageStmt,
+				deleteStmt: db.deleteMessageStmt,
+			}
+			err = res.Scan(&message.Id,
+				&message.Recipient,
+				&message.Subject,
+				&message.Body,
+				&message.QuoteIds,
+				&message.Status,
+				&message.Attempts,
+				&message.NextAttempt,
+				&message.LastError,
+				&message.CreatedDate,
+				&message.Text,
+				&message.SubscriptionId)
+			messages = append(messages, message)
+		}
+	}
+	return
+}
+
+func (db Database) GetFeedback() (feedback []Feedback, err error) {
+	var res *sql.Rows
+	if res, err = db.selectFeedbackStmt.Query(); res != nil {
+		for res.Next() && err == nil {
+			entry := Feedback{
+				stmt:       db.updateFeedbackStmt,
+				deleteStmt: db.deleteFeedbackStmt,
+			}
+			err = res.Scan(&entry.Id,
+				&entry.QuoteId,
+				&entry.Recipient,
+				&entry.Kind,
+				&entry.Until,
+				&entry.FeedbackDate)
+			feedback = append(feedback, entry)
+		}
+	}
+	return
+}
+
+// FeedbackOf returns the Feedback given by `recipient`
+func (db Database) FeedbackOf(recipient string) (feedback []Feedback, err error) {
+	var res *sql.Rows
+	if res, err = db.feedbackOfStmt.Query(recipient); res != nil {
+		for res.Next() && err == nil {
+			entry := Feedback{
+				stmt:       db.updateFeedbackStmt,
+				deleteStmt: db.deleteFeedbackStmt,
+			}
+			err = res.Scan(&entry.Id,
+				&entry.QuoteId,
+				&entry.Recipient,
+				&entry.Kind,
+				&entry.Until,
+				&entry.FeedbackDate)
+			feedback = append(feedback, entry)
+		}
+	}
+	return
+}
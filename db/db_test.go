@@ -1,10 +1,13 @@
 package quote
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"testing"
+	"time"
 )
 
 const (
@@ -1695,3 +1698,1044 @@ func TestInsertNewQuote(t *testing.T) {
 		t.Fatalf(insertionError, expectedId, actualId)
 	}
 }
+
+func TestInsertNewDelivery(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	delivery := database.NewDelivery(1, "to@mail.com")
+	// Act
+	actualId, err := delivery.Commit()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedId := 1
+	if actualId != expectedId {
+		t.Fatalf(insertionError, expectedId, actualId)
+	}
+}
+
+func TestGetDeliveries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, quoteId := range []int{1, 2, 1} {
+		_, err = database.NewDelivery(quoteId, "to@mail.com").Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	deliveries, err := database.GetDeliveries()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedStmt := database.updateDeliveryStmt
+	for _, delivery := range deliveries {
+		actualStmt := delivery.stmt
+		if actualStmt != expectedStmt {
+			t.Fatalf(stmtError, expectedStmt, actualStmt)
+		}
+	}
+	expectedLen := 3
+	if actualLen := len(deliveries); actualLen != expectedLen {
+		t.Fatalf(lenError, expectedLen, actualLen)
+	}
+	for i, expectedQuoteId := range []int{1, 2, 1} {
+		if actualQuoteId := deliveries[i].QuoteId; actualQuoteId != expectedQuoteId {
+			t.Errorf(idError, expectedQuoteId, actualQuoteId)
+		}
+	}
+}
+
+func TestSearchDeliveries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, recipient := range []string{"first@mail.com", "second@mail.com"} {
+		_, err = database.NewDelivery(1, recipient).Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	deliveries, err := database.SearchDeliveries("second")
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedLen := 1
+	if actualLen := len(deliveries); actualLen != expectedLen {
+		t.Fatalf(lenError, expectedLen, actualLen)
+	}
+	expectedRecipient := "second@mail.com"
+	if actualRecipient := deliveries[0].Recipient; actualRecipient != expectedRecipient {
+		t.Errorf(contentError, expectedRecipient, actualRecipient)
+	}
+}
+
+func TestDeliveryCounts(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	deliveries := []Delivery{
+		database.NewDelivery(1, "first@mail.com"),
+		database.NewDelivery(1, "first@mail.com"),
+		database.NewDelivery(2, "first@mail.com"),
+		database.NewDelivery(2, "second@mail.com"),
+	}
+	for _, delivery := range deliveries {
+		if _, err = delivery.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	counts, err := database.DeliveryCounts("first@mail.com")
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedCounts := map[int]int{1: 2, 2: 1}
+	if actualLen := len(counts); actualLen != len(expectedCounts) {
+		t.Fatalf(lenError, len(expectedCounts), actualLen)
+	}
+	for quoteId, expectedCount := range expectedCounts {
+		if actualCount := counts[quoteId]; actualCount != expectedCount {
+			t.Errorf(contentError, expectedCount, actualCount)
+		}
+	}
+}
+
+func TestReviewGrade(t *testing.T) {
+	// Arrange
+	date := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	review := Review{EaseFactor: 2.5}
+	grades := []int{5, 4, 4, 2, 3}
+	expectedIntervals := []int{1, 6, 16, 1, 1}
+	expectedEaseFactors := []float64{2.6, 2.6, 2.6, 2.28, 2.14}
+	for i, grade := range grades {
+		// Act
+		var err error
+		review, err = review.Grade(grade, date)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if review.Interval != expectedIntervals[i] {
+			t.Errorf(contentError, expectedIntervals[i], review.Interval)
+		}
+		if math.Abs(review.EaseFactor-expectedEaseFactors[i]) > 1e-9 {
+			t.Errorf(contentError, expectedEaseFactors[i], review.EaseFactor)
+		}
+		expectedDueDate := date.AddDate(0, 0, expectedIntervals[i])
+		if !review.DueDate.Equal(expectedDueDate) {
+			t.Errorf(contentError, expectedDueDate, review.DueDate)
+		}
+	}
+}
+
+func TestReviewGradeLimits(t *testing.T) {
+	// Arrange
+	review := Review{EaseFactor: 1.3}
+	// Act
+	_, errLow := review.Grade(-1, time.Now())
+	_, errHigh := review.Grade(6, time.Now())
+	graded, err := review.Grade(0, time.Now())
+	// Assert
+	if errLow == nil || errHigh == nil {
+		t.Error("Expected an error but got nil")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedEaseFactor := 1.3; graded.EaseFactor != expectedEaseFactor {
+		t.Errorf(contentError, expectedEaseFactor, graded.EaseFactor)
+	}
+}
+
+func TestInsertAndGetReview(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	review, err := database.NewReview(2).Grade(4, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	actualId, err := review.Commit()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedId := 1
+	if actualId != expectedId {
+		t.Fatalf(insertionError, expectedId, actualId)
+	}
+	stored, err := database.GetReview(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.stmt != database.updateReviewStmt {
+		t.Fatalf(stmtError, database.updateReviewStmt, stored.stmt)
+	}
+	if stored.Repetitions != review.Repetitions || stored.Interval != review.Interval {
+		t.Errorf(contentError, review, stored)
+	}
+	unreviewed, err := database.GetReview(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unreviewed != DefaultReview {
+		t.Errorf(contentError, DefaultReview, unreviewed)
+	}
+}
+
+func TestDueQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	now := time.Now()
+	review := database.NewReview(2)
+	review.DueDate = now.AddDate(0, 0, 3)
+	if _, err = review.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	dueNow, err := database.DueQuotes(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dueLater, err := database.DueQuotes(now.AddDate(0, 0, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	if expectedLen := 1; len(dueNow) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(dueNow))
+	}
+	if expectedId := 1; dueNow[0].Id != expectedId {
+		t.Errorf(idError, expectedId, dueNow[0].Id)
+	}
+	if expectedLen := 2; len(dueLater) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(dueLater))
+	}
+	// reviewed quotes come before the never reviewed ones
+	if expectedId := 2; dueLater[0].Id != expectedId {
+		t.Errorf(idError, expectedId, dueLater[0].Id)
+	}
+}
+
+func TestInsertAndGetSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	subscription := database.NewSubscription("to@mail.com")
+	subscription.Schedule = "weekly"
+	subscription.AuthorId = 2
+	// Act
+	actualId, err := subscription.Commit()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedId := 1
+	if actualId != expectedId {
+		t.Fatalf(insertionError, expectedId, actualId)
+	}
+	stored, err := database.GetSubscription(actualId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.stmt != database.updateSubscriptionStmt {
+		t.Fatalf(stmtError, database.updateSubscriptionStmt, stored.stmt)
+	}
+	subscription.Id = actualId
+	subscription.stmt = stored.stmt
+	if stored != subscription {
+		t.Errorf(contentError, subscription, stored)
+	}
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	id, err := database.NewSubscription("to@mail.com").Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription.Strategy = "book"
+	subscription.BookId = 1
+	subscription.TimeZone = "Europe/Berlin"
+	subscription.SendTime = "07:30"
+	subscription.QuietStart = "22:00"
+	subscription.QuietEnd = "06:00"
+	subscription.Quiz = true
+	// Act
+	actualId, err := subscription.Commit()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actualId != id {
+		t.Fatalf(idError, id, actualId)
+	}
+	stored, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != subscription {
+		t.Errorf(contentError, subscription, stored)
+	}
+}
+
+func TestSubscriptionSent(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	id, err := database.NewSubscription("to@mail.com").Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !subscription.LastSent.IsZero() {
+		t.Fatalf(contentError, time.Time{}, subscription.LastSent)
+	}
+	sent := time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC)
+	// Act
+	err = database.SubscriptionSent(id, sent)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	// updating the subscription keeps the time of its last reminder
+	subscription.Count = 3
+	if _, err = subscription.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.LastSent.Equal(sent) || stored.Count != 3 {
+		t.Errorf(contentError, sent, stored)
+	}
+}
+
+func TestSubscriptionProgress(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	subscription := database.NewSubscription("to@mail.com")
+	subscription.BookId = 1
+	subscription.SetQueue([]int{2, 3})
+	id, err := subscription.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = database.SubscriptionProgress(id, 2, []int{3}, 2, 69)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queue, err := stored.Queue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.BookId != 2 || stored.Cursor != 2 || stored.CursorPage != 69 ||
+		len(queue) != 1 || queue[0] != 3 {
+		t.Errorf(contentError, "book 2 at quote 2 followed by book 3", stored)
+	}
+}
+
+func TestGetAndSearchSubscriptions(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, recipient := range []string{"first@mail.com", "second@mail.com"} {
+		if _, err = database.NewSubscription(recipient).Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := database.SearchSubscriptions("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	if expectedLen := 2; len(subscriptions) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(subscriptions))
+	}
+	if expectedLen := 1; len(found) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(found))
+	}
+	if found[0] != subscriptions[0] {
+		t.Errorf(contentError, subscriptions[0], found[0])
+	}
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	id, err := database.NewSubscription("to@mail.com").Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = subscription.Delete()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != DefaultSubscription {
+		t.Errorf(contentError, DefaultSubscription, deleted)
+	}
+}
+
+func TestParseDeletePolicy(t *testing.T) {
+	// Arrange
+	cases := map[string]string{"": RestrictPolicy, "restrict": RestrictPolicy,
+		"Cascade": CascadePolicy}
+	for policy, expected := range cases {
+		// Act
+		actual, err := ParseDeletePolicy(policy)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf(contentError, expected, actual)
+		}
+	}
+	if _, err := ParseDeletePolicy("orphan"); err == nil {
+		t.Error("Expected an error for an unknown policy but got nil")
+	}
+}
+
+func TestDeleteQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err = database.NewDelivery(1, "to@mail.com").Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = database.NewReview(1).Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = database.NewFeedback(1, "to@mail.com", FeedbackFavorite).Commit(); err != nil {
+		t.Fatal(err)
+	}
+	quote, err := database.GetQuote(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = quote.Delete()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := database.GetQuote(1); err != nil || deleted != DefaultQuote {
+		t.Errorf(contentError, DefaultQuote, deleted)
+	}
+	if review, err := database.GetReview(1); err != nil || review != DefaultReview {
+		t.Errorf(contentError, DefaultReview, review)
+	}
+	deliveries, err := database.GetDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.QuoteId == 1 {
+			t.Errorf(contentError, "no deliveries of quote 1", delivery)
+		}
+	}
+	feedback, err := database.GetFeedback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 0 {
+		t.Errorf(lenError, 0, len(feedback))
+	}
+	// the book of the quote is kept
+	if book, err := database.GetBook(1); err != nil || book.Id != 1 {
+		t.Errorf(idError, 1, book.Id)
+	}
+}
+
+func TestDeleteBookWithDependents(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = book.Delete()
+	// Assert
+	if !errors.Is(err, ErrDependents) {
+		t.Fatalf(contentError, ErrDependents, err)
+	}
+	if kept, err := database.GetBook(1); err != nil || kept.Id != 1 {
+		t.Errorf(idError, 1, kept.Id)
+	}
+	if quote, err := database.GetQuote(1); err != nil || quote.Id != 1 {
+		t.Errorf(idError, 1, quote.Id)
+	}
+}
+
+func TestDeleteUnusedTopic(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	topic := database.NewTopic()
+	topic.Topic = "Unused"
+	if topic.Id, err = topic.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = topic.Delete()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := database.GetTopic(topic.Id); err != nil || deleted != DefaultTopic {
+		t.Errorf(contentError, DefaultTopic, deleted)
+	}
+}
+
+func TestDeleteAuthorCascade(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	ofAuthor := database.NewSubscription("author@mail.com")
+	ofAuthor.AuthorId = 1
+	ofBook := database.NewSubscription("book@mail.com")
+	ofBook.BookId = 1
+	other := database.NewSubscription("other@mail.com")
+	other.AuthorId = 2
+	for _, subscription := range []Subscription{ofAuthor, ofBook, other} {
+		if _, err = subscription.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = database.NewDelivery(1, "to@mail.com").Commit(); err != nil {
+		t.Fatal(err)
+	}
+	author, err := database.GetAuthor(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = author.DeleteCascade()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := database.GetAuthor(1); err != nil || deleted != DefaultAuthor {
+		t.Errorf(contentError, DefaultAuthor, deleted)
+	}
+	if deleted, err := database.GetBook(1); err != nil || deleted != DefaultBook {
+		t.Errorf(contentError, DefaultBook, deleted)
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 1 || quotes[0].Id != 2 {
+		t.Errorf(contentError, "quote 2", quotes)
+	}
+	deliveries, err := database.GetDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, delivery := range deliveries {
+		if delivery.QuoteId == 1 {
+			t.Errorf(contentError, "no deliveries of quote 1", delivery)
+		}
+	}
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription.Recipient == "author@mail.com" || subscription.Recipient == "book@mail.com" {
+			t.Errorf(contentError, "no subscriptions of author 1", subscription)
+		}
+	}
+	if len(subscriptions) == 0 {
+		t.Errorf(contentError, "subscription of author 2", subscriptions)
+	}
+}
+
+func TestDeleteBookAdvancesSeries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	series := database.NewSubscription("series@mail.com")
+	series.BookId = 1
+	series.SetQueue([]int{2, 3})
+	if series.Id, err = series.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = database.SubscriptionProgress(series.Id, 1, []int{2, 3}, 1, 69); err != nil {
+		t.Fatal(err)
+	}
+	last := database.NewSubscription("last@mail.com")
+	last.BookId = 1
+	if _, err = last.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = book.DeleteCascade()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := database.GetSubscription(series.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.BookId != 2 || stored.BookQueue != "3" || stored.Cursor != 0 || stored.CursorPage != 0 {
+		t.Errorf(contentError, "series at the beginning of book 2", stored)
+	}
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription.Recipient == "last@mail.com" {
+			t.Errorf(contentError, "no subscription without further books", subscription)
+		}
+	}
+}
+
+func TestDeleteDeliveryAndMessage(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	deliveryId, err := database.NewDelivery(1, "to@mail.com").Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageId, err := database.NewMessage("to@mail.com", "Quote-reminder", "body", []int{1}).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery, err := database.GetDelivery(deliveryId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := database.GetMessage(messageId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	if err = delivery.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err = message.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	if deleted, err := database.GetDelivery(deliveryId); err != nil || deleted != DefaultDelivery {
+		t.Errorf(contentError, DefaultDelivery, deleted)
+	}
+	if deleted, err := database.GetMessage(messageId); err != nil || deleted != DefaultMessage {
+		t.Errorf(contentError, DefaultMessage, deleted)
+	}
+}
+
+func TestInsertAndGetMessage(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	message := database.NewMessage("to@mail.com", "Quote-reminder", "body", []int{1, 2})
+	// Act
+	id, err := message.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := database.GetMessage(id)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Recipient != message.Recipient || actual.Body != message.Body ||
+		actual.Status != MessagePending || actual.Attempts != 0 {
+		t.Errorf(contentError, message, actual)
+	}
+	ids, err := actual.Quotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf(contentError, []int{1, 2}, ids)
+	}
+}
+
+func TestPendingMessages(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	now := time.Now().Add(time.Minute)
+	due := database.NewMessage("due@mail.com", "", "", nil)
+	later := database.NewMessage("later@mail.com", "", "", nil)
+	later.NextAttempt = now.Add(time.Hour)
+	failed := database.NewMessage("failed@mail.com", "", "", nil)
+	failed.Status = MessageFailed
+	for _, message := range []Message{due, later, failed} {
+		if _, err = message.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	pending, err := database.PendingMessages(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failedMessages, err := database.MessagesOfStatus(MessageFailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	if expectedLen := 1; len(pending) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(pending))
+	}
+	if pending[0].Recipient != due.Recipient {
+		t.Errorf(contentError, due.Recipient, pending[0].Recipient)
+	}
+	if expectedLen := 1; len(failedMessages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(failedMessages))
+	}
+	if failedMessages[0].Recipient != failed.Recipient {
+		t.Errorf(contentError, failed.Recipient, failedMessages[0].Recipient)
+	}
+}
+
+func TestPendingMessagesOf(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	first := database.NewMessage("first@mail.com", "", "", nil)
+	first.SubscriptionId = 1
+	other := database.NewMessage("other@mail.com", "", "", nil)
+	other.SubscriptionId = 2
+	sent := database.NewMessage("sent@mail.com", "", "", nil)
+	sent.SubscriptionId = 1
+	sent.Status = MessageSent
+	second := database.NewMessage("second@mail.com", "", "", nil)
+	second.SubscriptionId = 1
+	second.NextAttempt = time.Now().Add(time.Hour)
+	for _, message := range []Message{first, other, sent, second} {
+		if _, err = message.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	pending, err := database.PendingMessagesOf(1)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 2; len(pending) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(pending))
+	}
+	for i, expected := range []Message{first, second} {
+		if pending[i].Recipient != expected.Recipient {
+			t.Errorf(contentError, expected.Recipient, pending[i].Recipient)
+		}
+	}
+}
+
+func TestInsertAndGetFeedback(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	snooze := database.NewFeedback(1, "to@mail.com", FeedbackSnooze)
+	snooze.Until = time.Now().Add(time.Hour)
+	entries := []Feedback{
+		database.NewFeedback(1, "to@mail.com", FeedbackFavorite),
+		database.NewFeedback(2, "other@mail.com", FeedbackLess),
+		snooze,
+	}
+	// Act
+	for _, entry := range entries {
+		if _, err = entry.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, err := database.GetFeedback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	own, err := database.FeedbackOf("to@mail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	if expectedLen := 3; len(all) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(all))
+	}
+	if expectedLen := 2; len(own) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(own))
+	}
+	for _, entry := range own {
+		if entry.Recipient != "to@mail.com" {
+			t.Errorf(contentError, "to@mail.com", entry.Recipient)
+		}
+		if entry.Kind == FeedbackSnooze && !entry.Until.Equal(snooze.Until.UTC().Truncate(time.Second)) {
+			t.Errorf(contentError, snooze.Until, entry.Until)
+		}
+	}
+}
+
+func TestRecordedQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	quote, err := database.GetQuote(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// recorded today
+	newQuote := database.NewQuote(quote.Book)
+	newQuote.Quote = "Quote3"
+	newQuote.Page = 70
+	if _, err = newQuote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	// Act
+	thisWeek, err := database.RecordedQuotes(now.AddDate(0, 0, -7), now.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past, err := database.RecordedQuotes(time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1999, 1, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	if expectedLen := 1; len(thisWeek) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(thisWeek))
+	}
+	if thisWeek[0].Quote != "Quote3" {
+		t.Errorf(contentError, "Quote3", thisWeek[0].Quote)
+	}
+	if expectedLen := 2; len(past) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(past))
+	}
+}
+
+func TestQuotesPerTopicAndAuthor(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newQuote := database.NewQuote(book)
+	newQuote.Quote = "Quote3"
+	newQuote.Page = 70
+	if _, err = newQuote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	topics, err := database.QuotesPerTopic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors, err := database.QuotesPerAuthor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	expectedTopics := []Tally{{2, "Topic2", 2}, {1, "Topic1", 1}}
+	if len(topics) != len(expectedTopics) || topics[0] != expectedTopics[0] || topics[1] != expectedTopics[1] {
+		t.Errorf(contentError, expectedTopics, topics)
+	}
+	expectedAuthors := []Tally{{2, "Author2", 2}, {1, "Author1", 1}}
+	if len(authors) != len(expectedAuthors) || authors[0] != expectedAuthors[0] || authors[1] != expectedAuthors[1] {
+		t.Errorf(contentError, expectedAuthors, authors)
+	}
+}
+
+func TestFavoriteQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, entry := range []Feedback{
+		database.NewFeedback(2, "first@mail.com", FeedbackFavorite),
+		database.NewFeedback(2, "second@mail.com", FeedbackFavorite),
+		database.NewFeedback(1, "first@mail.com", FeedbackFavorite),
+		database.NewFeedback(1, "second@mail.com", FeedbackLess),
+	} {
+		if _, err = entry.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Act
+	favorites, err := database.FavoriteQuotes(1)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Tally{{2, "Quote2", 2}}
+	if len(favorites) != 1 || favorites[0] != expected[0] {
+		t.Errorf(contentError, expected, favorites)
+	}
+}
+
+func TestCollectionSize(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	size, err := database.CollectionSize()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Size{Quotes: 2, Books: 2, Authors: 2, Topics: 2, Languages: 2}
+	if size != expected {
+		t.Errorf(contentError, expected, size)
+	}
+}
@@ -0,0 +1,372 @@
+package quote
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles are the migration scripts, named `<version>_<name>.sql`
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration evolves the schema of the Database from the previous version to
+// its Version
+type Migration struct {
+	Version int
+	Name    string
+	Script  string
+}
+
+// MigrationStatus tells whether a migration was applied to the Database
+type MigrationStatus struct {
+	Migration
+	Applied     bool
+	AppliedDate time.Time
+}
+
+// create the table of the applied migrations
+const createSchemaVersion = `CREATE TABLE IF NOT EXISTS schema_version (
+Version INTEGER PRIMARY KEY,
+Name varchar NOT NULL,
+AppliedDate datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+// statements of the applied migrations
+const (
+	countSchemaVersion = `SELECT COUNT(*) FROM sqlite_master
+WHERE type = 'table' AND name = 'schema_version'`
+	selectSchemaVersions = `SELECT Version, AppliedDate FROM schema_version`
+	insertSchemaVersion  = `INSERT INTO schema_version (Version, Name, AppliedDate)
+VALUES (?, ?, ?)`
+)
+
+// statements inspecting the schema of a database created before the
+// migrations
+const (
+	countTables = `SELECT COUNT(*) FROM sqlite_master
+WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'schema_version'`
+	tableInfo = `PRAGMA table_info(%q)`
+)
+
+// statements of a migration whose changes can be found in the schema
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\((.*)\)\s*;?$`)
+	addColumnPattern   = regexp.MustCompile(`(?is)^ALTER TABLE (\w+) ADD COLUMN (\w+)`)
+)
+
+// constraints of a table, which are not columns
+var tableConstraints = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "CHECK": true, "FOREIGN": true,
+}
+
+// Migrations returns the migrations ordered by their version
+func Migrations() (migrations []Migration, err error) {
+	names, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return
+	}
+	for _, file := range names {
+		name := strings.TrimSuffix(file.Name(), ".sql")
+		parts := strings.SplitN(name, "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 || version < 1 {
+			return nil, fmt.Errorf("invalid migration name %s", file.Name())
+		}
+		script, err := migrationFiles.ReadFile(path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    parts[1],
+			Script:  string(script),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("missing migration of version %d", i+1)
+		}
+	}
+	return
+}
+
+// statements splits the `script` of a migration into its statements, the
+// statements of a trigger body are kept together with the trigger
+func statements(script string) (stmts []string) {
+	var stmt []string
+	body := false
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		stmt = append(stmt, line)
+		switch upper := strings.ToUpper(trimmed); {
+		case upper == "BEGIN":
+			body = true
+		case upper == "END;":
+			body = false
+		}
+		if !body && strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.Join(stmt, "\n"))
+			stmt = nil
+		}
+	}
+	if len(stmt) > 0 {
+		stmts = append(stmts, strings.Join(stmt, "\n"))
+	}
+	return
+}
+
+// MigrationStatus returns the status of every migration ordered by their
+// version. It does not change the Database, none of the migrations are
+// applied if it has no schema_version table yet.
+func (db *Database) MigrationStatus() (status []MigrationStatus, err error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return
+	}
+	applied, err := db.appliedVersions()
+	if err != nil {
+		return
+	}
+	for version := range applied {
+		if version > len(migrations) {
+			return nil, fmt.Errorf("schema version %d of the database is newer than the latest migration %d",
+				version, len(migrations))
+		}
+	}
+	for _, migration := range migrations {
+		date, ok := applied[migration.Version]
+		status = append(status, MigrationStatus{
+			Migration:   migration,
+			Applied:     ok,
+			AppliedDate: date,
+		})
+	}
+	return
+}
+
+// appliedVersions returns the dates of the applied migrations by their
+// version, which are read from the schema_version table if it exists
+func (db *Database) appliedVersions() (applied map[int]time.Time, err error) {
+	var tables int
+	if err = db.connection.QueryRow(countSchemaVersion).Scan(&tables); err != nil || tables == 0 {
+		return
+	}
+	rows, err := db.connection.Query(selectSchemaVersions)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	applied = make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var date time.Time
+		if err = rows.Scan(&version, &date); err != nil {
+			return
+		}
+		applied[version] = date
+	}
+	err = rows.Err()
+	return
+}
+
+// SchemaVersion returns the version of the latest migration applied to the
+// Database, 0 if none was applied yet
+func (db *Database) SchemaVersion() (version int, err error) {
+	status, err := db.MigrationStatus()
+	if err != nil {
+		return
+	}
+	for _, migration := range status {
+		if migration.Applied {
+			version = migration.Version
+		}
+	}
+	return
+}
+
+// columns returns the columns of `table`, which has none if it does not exist
+func (db *Database) columns(table string) (columns map[string]bool, err error) {
+	rows, err := db.connection.Query(fmt.Sprintf(tableInfo, table))
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	columns = make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, kind string
+		var value sql.NullString
+		if err = rows.Scan(&cid, &name, &kind, &notNull, &value, &pk); err != nil {
+			return
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	err = rows.Err()
+	return
+}
+
+// legacyMigrations returns the migrations whose tables and columns exist in a
+// Database which was created before the migrations, as they were applied by
+// its initialization. Migrations without tables or columns (e.g. triggers)
+// are applied again, their scripts do not fail if they exist already. A
+// migration whose tables and columns only exist in parts is an error.
+func (db *Database) legacyMigrations(migrations []Migration) (legacy []Migration, err error) {
+	var tables int
+	if err = db.connection.QueryRow(countTables).Scan(&tables); err != nil || tables == 0 {
+		return
+	}
+	schema := make(map[string]map[string]bool)
+	exists := func(table, column string) (bool, error) {
+		table = strings.ToLower(table)
+		if _, ok := schema[table]; !ok {
+			columns, err := db.columns(table)
+			if err != nil {
+				return false, err
+			}
+			schema[table] = columns
+		}
+		return schema[table][strings.ToLower(column)], nil
+	}
+	for _, migration := range migrations {
+		found, missing := 0, 0
+		for _, stmt := range statements(migration.Script) {
+			stmt = strings.TrimSpace(stmt)
+			var table string
+			var columns []string
+			if match := createTablePattern.FindStringSubmatch(stmt); match != nil {
+				table = match[1]
+				for _, definition := range strings.Split(match[2], "\n") {
+					fields := strings.Fields(definition)
+					if len(fields) > 0 && !tableConstraints[strings.ToUpper(fields[0])] {
+						columns = append(columns, fields[0])
+					}
+				}
+			} else if match := addColumnPattern.FindStringSubmatch(stmt); match != nil {
+				table, columns = match[1], []string{match[2]}
+			}
+			for _, column := range columns {
+				ok, err := exists(table, column)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					found++
+				} else {
+					missing++
+				}
+			}
+		}
+		switch {
+		case found > 0 && missing > 0:
+			return nil, fmt.Errorf("the schema of the database only contains parts of migration %d_%s",
+				migration.Version, migration.Name)
+		case found > 0:
+			legacy = append(legacy, migration)
+		}
+	}
+	return
+}
+
+// record the `migrations` as applied without applying them
+func (db *Database) record(migrations []Migration) (err error) {
+	tx, err := db.connection.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	for _, migration := range migrations {
+		_, err = tx.Exec(insertSchemaVersion, migration.Version, migration.Name, time.Now())
+		if err != nil {
+			return
+		}
+	}
+	return tx.Commit()
+}
+
+// Migrate applies the pending migrations to the Database in the order of their
+// version, each of them in a transaction together with its entry in the
+// schema_version table. Returns the migrations which were applied, a failing
+// migration is rolled back and ends the migration. The migrations which are
+// found in the schema of a Database created before the migrations are
+// recorded as applied first (see legacyMigrations).
+func (db *Database) Migrate() (applied []Migration, err error) {
+	status, err := db.MigrationStatus()
+	if err != nil {
+		return
+	}
+	if _, err = db.connection.Exec(createSchemaVersion); err != nil {
+		return
+	}
+	migrations := make([]Migration, len(status))
+	recorded := false
+	for i, migration := range status {
+		migrations[i] = migration.Migration
+		recorded = recorded || migration.Applied
+	}
+	if !recorded {
+		var legacy []Migration
+		if legacy, err = db.legacyMigrations(migrations); err != nil {
+			return
+		}
+		if err = db.record(legacy); err != nil {
+			return
+		}
+		for _, migration := range legacy {
+			status[migration.Version-1].Applied = true
+		}
+	}
+	for _, migration := range status {
+		if migration.Applied {
+			continue
+		}
+		err = db.apply(migration.Migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d_%s failed: %w",
+				migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration.Migration)
+	}
+	return
+}
+
+// apply the `migration` in a transaction
+func (db *Database) apply(migration Migration) (err error) {
+	tx, err := db.connection.Begin()
+	if err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+	for _, stmt := range statements(migration.Script) {
+		_, err = tx.Exec(stmt)
+		if err != nil {
+			return
+		}
+	}
+	_, err = tx.Exec(insertSchemaVersion, migration.Version, migration.Name, time.Now())
+	if err != nil {
+		return
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,305 @@
+package quote
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	// Act
+	migrations, err := Migrations()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations but got none")
+	}
+	for i, migration := range migrations {
+		if expected, actual := i+1, migration.Version; actual != expected {
+			t.Errorf(contentError, expected, actual)
+		}
+		if migration.Name == "" || migration.Script == "" {
+			t.Errorf("Migration %d has no name or script", migration.Version)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	// Arrange
+	script := `-- comment
+CREATE TABLE Tests (
+Id INTEGER PRIMARY KEY
+);
+
+CREATE TRIGGER TestDeleted AFTER DELETE ON Tests
+BEGIN
+DELETE FROM Others WHERE TestId = OLD.Id;
+DELETE FROM Rest WHERE TestId = OLD.Id;
+END;
+ALTER TABLE Tests ADD COLUMN Name varchar;`
+	// Act
+	actual := statements(script)
+	// Assert
+	expected := []string{
+		"CREATE TABLE Tests (\nId INTEGER PRIMARY KEY\n);",
+		"CREATE TRIGGER TestDeleted AFTER DELETE ON Tests\nBEGIN\nDELETE FROM Others WHERE TestId = OLD.Id;\nDELETE FROM Rest WHERE TestId = OLD.Id;\nEND;",
+		"ALTER TABLE Tests ADD COLUMN Name varchar;",
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(actual))
+	}
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf(contentError, expected[i], actual[i])
+		}
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	// Arrange
+	database, err := Open(filepath.Join(t.TempDir(), "new.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	applied, err := database.Migrate()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf(lenError, len(migrations), len(applied))
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := len(migrations); version != expected {
+		t.Errorf(contentError, expected, version)
+	}
+	status, err := database.MigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range status {
+		if !migration.Applied || migration.AppliedDate.IsZero() {
+			t.Errorf("Migration %d was not applied", migration.Version)
+		}
+	}
+}
+
+func TestMigrationStatusReadOnly(t *testing.T) {
+	// Arrange
+	database, err := Open(filepath.Join(t.TempDir(), "new.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	status, err := database.MigrationStatus()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range status {
+		if migration.Applied {
+			t.Errorf("Migration %d is applied", migration.Version)
+		}
+	}
+	var count int
+	err = database.connection.QueryRow("SELECT COUNT(*) FROM sqlite_master").Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf(contentError, "no tables", count)
+	}
+}
+
+func TestMigrateTwice(t *testing.T) {
+	// Arrange
+	database, err := Open(filepath.Join(t.TempDir(), "new.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err = database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	applied, err := database.Migrate()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf(lenError, 0, len(applied))
+	}
+}
+
+// legacySubscriptions creates the subscriptions of a database created before
+// the migrations, whose initialization added the columns of migration 7 and 8
+func legacySubscriptions(t *testing.T, database *Database, migrations []Migration) {
+	for _, version := range []int{4, 7, 8} {
+		for _, stmt := range statements(migrations[version-1].Script) {
+			if _, err := database.connection.Exec(stmt); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Open(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacySubscriptions(t, database, migrations)
+	// Act
+	applied, err := database.Migrate()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the migrations found in the schema are recorded without applying them
+	for _, migration := range applied {
+		switch migration.Version {
+		case 1, 4, 7, 8:
+			t.Errorf("Migration %d was applied again", migration.Version)
+		}
+	}
+	if expected, actual := len(migrations)-4, len(applied); actual != expected {
+		t.Errorf(lenError, expected, actual)
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := len(migrations); version != expected {
+		t.Errorf(contentError, expected, version)
+	}
+	if err = database.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := 2, len(quotes); actual != expected {
+		t.Errorf(lenError, expected, actual)
+	}
+	if _, err = database.NewSubscription("to@mail.com").Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = database.GetSubscriptions(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigratePartialDatabase(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Open(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// the subscriptions have only some of the columns of migration 4
+	_, err = database.connection.Exec(`CREATE TABLE Subscriptions (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+Recipient varchar NOT NULL
+);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	applied, err := database.Migrate()
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for a partially migrated database but got nil")
+	}
+	if len(applied) != 0 {
+		t.Errorf(lenError, 0, len(applied))
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Errorf(contentError, 0, version)
+	}
+}
+
+func TestMigrateFailingMigration(t *testing.T) {
+	// Arrange
+	database, err := Open(filepath.Join(t.TempDir(), "new.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	migration := Migration{
+		Version: 1,
+		Name:    "failing",
+		Script:  "CREATE TABLE Tests (Id INTEGER);\nINSERT INTO Missing VALUES (1);",
+	}
+	if _, err = database.connection.Exec(createSchemaVersion); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err = database.apply(migration)
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error of the migration but got nil")
+	}
+	var count int
+	err = database.connection.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE name = 'Tests'").Scan(&count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("Expected the failing migration to be rolled back")
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Errorf(contentError, 0, version)
+	}
+}
+
+func TestMigrateNewerDatabase(t *testing.T) {
+	// Arrange
+	database, err := Open(filepath.Join(t.TempDir(), "new.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err = database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = database.connection.Exec(insertSchemaVersion, 9999, "future", "2030-01-01 00:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	_, err = database.Migrate()
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for a newer schema version but got nil")
+	}
+}
@@ -0,0 +1,38 @@
+-- the tables of the quotes and their books, which databases created before the
+-- migrations already have
+CREATE TABLE IF NOT EXISTS Topics (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+Topic varchar NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS Authors (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+Name varchar NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS Languages (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+Language varchar NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS Books (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+AuthorId INTEGER NOT NULL,
+TopicId INTEGER NOT NULL,
+ISBN varchar UNIQUE,
+Title varchar NOT NULL,
+LanguageId INTEGER NOT NULL,
+ReleaseDate date NOT NULL,
+FOREIGN KEY (AuthorId) REFERENCES Authors(Id),
+FOREIGN KEY (TopicId) REFERENCES Topics(Id),
+FOREIGN KEY (LanguageId) REFERENCES Languages(Id)
+);
+
+CREATE TABLE IF NOT EXISTS Quotes (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+BookId INTEGER NOT NULL,
+Quote varchar NOT NULL,
+Page INTEGER NOT NULL,
+RecordDate date NOT NULL DEFAULT CURRENT_DATE,
+FOREIGN KEY (BookId) REFERENCES Books(Id)
+);
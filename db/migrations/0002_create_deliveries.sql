@@ -0,0 +1,8 @@
+-- the delivery history of the quotes
+CREATE TABLE IF NOT EXISTS Deliveries (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+QuoteId INTEGER NOT NULL,
+Recipient varchar NOT NULL,
+DeliveryDate datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+FOREIGN KEY (QuoteId) REFERENCES Quotes(Id)
+);
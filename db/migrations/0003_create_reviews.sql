@@ -0,0 +1,10 @@
+-- the spaced repetition reviews of the quotes
+CREATE TABLE IF NOT EXISTS Reviews (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+QuoteId INTEGER NOT NULL UNIQUE,
+EaseFactor REAL NOT NULL DEFAULT 2.5,
+Interval INTEGER NOT NULL DEFAULT 0,
+Repetitions INTEGER NOT NULL DEFAULT 0,
+DueDate datetime NOT NULL,
+FOREIGN KEY (QuoteId) REFERENCES Quotes(Id)
+);
@@ -0,0 +1,12 @@
+-- the subscriptions of the recipients of reminders
+CREATE TABLE IF NOT EXISTS Subscriptions (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+Recipient varchar NOT NULL,
+Schedule varchar NOT NULL DEFAULT 'daily',
+Count INTEGER NOT NULL DEFAULT 5,
+Strategy varchar NOT NULL DEFAULT 'random',
+TopicId INTEGER NOT NULL DEFAULT 0,
+AuthorId INTEGER NOT NULL DEFAULT 0,
+LanguageId INTEGER NOT NULL DEFAULT 0,
+BookId INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,13 @@
+-- the outbox of the reminders waiting to be sent
+CREATE TABLE IF NOT EXISTS Outbox (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+Recipient varchar NOT NULL,
+Subject varchar NOT NULL,
+Body varchar NOT NULL,
+QuoteIds varchar NOT NULL DEFAULT '',
+Status varchar NOT NULL DEFAULT 'pending',
+Attempts INTEGER NOT NULL DEFAULT 0,
+NextAttempt datetime NOT NULL,
+LastError varchar NOT NULL DEFAULT '',
+CreatedDate datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
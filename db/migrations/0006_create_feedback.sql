@@ -0,0 +1,10 @@
+-- the feedback of the recipients on the quotes they received
+CREATE TABLE IF NOT EXISTS Feedback (
+Id INTEGER PRIMARY KEY AUTOINCREMENT,
+QuoteId INTEGER NOT NULL,
+Recipient varchar NOT NULL,
+Kind varchar NOT NULL,
+Until datetime NOT NULL,
+FeedbackDate datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+FOREIGN KEY (QuoteId) REFERENCES Quotes(Id)
+);
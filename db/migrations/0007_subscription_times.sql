@@ -0,0 +1,6 @@
+-- the time zones, send times and quiet hours of the subscriptions
+ALTER TABLE Subscriptions ADD COLUMN TimeZone varchar NOT NULL DEFAULT '';
+ALTER TABLE Subscriptions ADD COLUMN SendTime varchar NOT NULL DEFAULT '';
+ALTER TABLE Subscriptions ADD COLUMN QuietStart varchar NOT NULL DEFAULT '';
+ALTER TABLE Subscriptions ADD COLUMN QuietEnd varchar NOT NULL DEFAULT '';
+ALTER TABLE Subscriptions ADD COLUMN LastSent datetime NOT NULL DEFAULT '0001-01-01 00:00:00';
@@ -0,0 +1,2 @@
+-- the quiz mode of the subscriptions
+ALTER TABLE Subscriptions ADD COLUMN Quiz INTEGER NOT NULL DEFAULT 0;
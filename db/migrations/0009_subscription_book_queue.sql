@@ -0,0 +1,3 @@
+-- the progress of the subscriptions through a book series
+ALTER TABLE Subscriptions ADD COLUMN BookQueue varchar NOT NULL DEFAULT '';
+ALTER TABLE Subscriptions ADD COLUMN Cursor INTEGER NOT NULL DEFAULT 0;
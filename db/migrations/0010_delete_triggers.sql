@@ -0,0 +1,32 @@
+-- triggers deleting the entries which depend on a deleted entry, the entries of
+-- a quote (e.g. its deliveries) only describe it and are always deleted with it
+CREATE TRIGGER IF NOT EXISTS QuoteDeleted AFTER DELETE ON Quotes
+BEGIN
+DELETE FROM Deliveries WHERE QuoteId = OLD.Id;
+DELETE FROM Reviews WHERE QuoteId = OLD.Id;
+DELETE FROM Feedback WHERE QuoteId = OLD.Id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS BookDeleted AFTER DELETE ON Books
+BEGIN
+DELETE FROM Quotes WHERE BookId = OLD.Id;
+DELETE FROM Subscriptions WHERE BookId = OLD.Id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS TopicDeleted AFTER DELETE ON Topics
+BEGIN
+DELETE FROM Books WHERE TopicId = OLD.Id;
+DELETE FROM Subscriptions WHERE TopicId = OLD.Id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS AuthorDeleted AFTER DELETE ON Authors
+BEGIN
+DELETE FROM Books WHERE AuthorId = OLD.Id;
+DELETE FROM Subscriptions WHERE AuthorId = OLD.Id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS LanguageDeleted AFTER DELETE ON Languages
+BEGIN
+DELETE FROM Books WHERE LanguageId = OLD.Id;
+DELETE FROM Subscriptions WHERE LanguageId = OLD.Id;
+END;
@@ -0,0 +1,3 @@
+-- the plain text of the messages of the outbox, which is delivered to the
+-- recipients without mail
+ALTER TABLE Outbox ADD COLUMN Text varchar NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- the position of a series is the page and id of the quote sent last, so that
+-- it is kept when the quote is deleted or moved to another page
+ALTER TABLE Subscriptions ADD COLUMN CursorPage INTEGER NOT NULL DEFAULT 0;
+UPDATE Subscriptions SET CursorPage = COALESCE(
+(SELECT Page FROM Quotes WHERE Quotes.Id = Subscriptions.Cursor), 0);
+
+-- the subscription of the messages of the outbox, whose series advances when
+-- they are delivered
+ALTER TABLE Outbox ADD COLUMN SubscriptionId INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+-- a series continues with the next book of its queue when its current book is
+-- deleted, only subscriptions without further books are deleted with it
+DROP TRIGGER IF EXISTS BookDeleted;
+
+CREATE TRIGGER BookDeleted AFTER DELETE ON Books
+BEGIN
+DELETE FROM Quotes WHERE BookId = OLD.Id;
+UPDATE Subscriptions SET
+BookId = CAST(CASE WHEN instr(BookQueue, ',') > 0
+THEN substr(BookQueue, 1, instr(BookQueue, ',') - 1)
+ELSE BookQueue END AS INTEGER),
+BookQueue = CASE WHEN instr(BookQueue, ',') > 0
+THEN substr(BookQueue, instr(BookQueue, ',') + 1)
+ELSE '' END,
+Cursor = 0,
+CursorPage = 0
+WHERE BookId = OLD.Id AND BookQueue != '';
+DELETE FROM Subscriptions WHERE BookId = OLD.Id;
+END;
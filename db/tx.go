@@ -0,0 +1,181 @@
+package quote
+
+import "database/sql"
+
+// Tx is a unit of work on the Database. The DAOs created and read with a Tx
+// commit and delete within its transaction, so that either all of their
+// changes are stored or none of them. Only the statements of the DAOs used
+// in a unit of work are bound to its transaction, when they are used first.
+type Tx struct {
+	// db is the Database the transaction was started on
+	db *Database
+	// bound are the statements of db bound to the transaction, it has no
+	// connection so that nothing runs outside of the transaction
+	bound Database
+	tx    *sql.Tx
+}
+
+// WithTx runs `work` in a transaction, which is committed if `work` returns
+// nil and rolled back if it returns an error or panics. The DAOs used by
+// `work` have to be created or read with its Tx, DAOs passed to NewQuote and
+// NewBook of the Tx are bound to the transaction.
+func (db *Database) WithTx(work func(tx *Tx) error) (err error) {
+	sqlTx, err := db.connection.Begin()
+	if err != nil {
+		return
+	}
+	tx := &Tx{db: db, tx: sqlTx}
+	defer func() {
+		if p := recover(); p != nil {
+			sqlTx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			sqlTx.Rollback()
+		}
+	}()
+	err = work(tx)
+	if err != nil {
+		return
+	}
+	return sqlTx.Commit()
+}
+
+// WithTx runs `work` in the transaction of the Tx, so that units of work can
+// be nested. Its error rolls back the whole transaction.
+func (tx *Tx) WithTx(work func(tx *Tx) error) error {
+	return work(tx)
+}
+
+// bind binds `stmt` of the Database to the transaction as `bound`, unless it
+// is bound already. The statement prepared on the connection of the
+// transaction is reused.
+func (tx *Tx) bind(bound **sql.Stmt, stmt *sql.Stmt) {
+	if *bound == nil {
+		*bound = tx.tx.Stmt(stmt)
+	}
+}
+
+// NewQuote creates a Quote of `book`, which is committed with the quote in
+// the transaction
+func (tx *Tx) NewQuote(book Book) Quote {
+	tx.bind(&tx.bound.insertQuoteStmt, tx.db.insertQuoteStmt)
+	tx.bind(&tx.bound.deleteQuoteStmt, tx.db.deleteQuoteStmt)
+	return tx.bound.NewQuote(tx.book(book))
+}
+
+// NewBook creates a Book of `author`, `topic` and `language`, which are
+// committed with the book in the transaction
+func (tx *Tx) NewBook(author Author, topic Topic, language Language) Book {
+	return tx.book(Book{Author: author, Topic: topic, Language: language})
+}
+
+// NewAuthor creates an Author which is committed in the transaction
+func (tx *Tx) NewAuthor() Author {
+	return tx.author(Author{})
+}
+
+// NewTopic creates a Topic which is committed in the transaction
+func (tx *Tx) NewTopic() Topic {
+	return tx.topic(Topic{})
+}
+
+// NewLanguage creates a Language which is committed in the transaction
+func (tx *Tx) NewLanguage() Language {
+	return tx.language(Language{})
+}
+
+// NewDelivery creates a Delivery which is committed in the transaction
+func (tx *Tx) NewDelivery(quoteId int, recipient string) Delivery {
+	tx.bind(&tx.bound.insertDeliveryStmt, tx.db.insertDeliveryStmt)
+	tx.bind(&tx.bound.deleteDeliveryStmt, tx.db.deleteDeliveryStmt)
+	return tx.bound.NewDelivery(quoteId, recipient)
+}
+
+// NewMessage creates a Message of the outbox which is committed in the
+// transaction
+func (tx *Tx) NewMessage(recipient, subject, body string, quoteIds []int) Message {
+	tx.bind(&tx.bound.insertMessageStmt, tx.db.insertMessageStmt)
+	tx.bind(&tx.bound.deleteMessageStmt, tx.db.deleteMessageStmt)
+	return tx.bound.NewMessage(recipient, subject, body, quoteIds)
+}
+
+// GetSubscriptions reads the subscriptions in the transaction, which are
+// committed and deleted in it as well
+func (tx *Tx) GetSubscriptions() ([]Subscription, error) {
+	tx.bind(&tx.bound.selectSubscriptionsStmt, tx.db.selectSubscriptionsStmt)
+	tx.bind(&tx.bound.updateSubscriptionStmt, tx.db.updateSubscriptionStmt)
+	tx.bind(&tx.bound.deleteSubscriptionStmt, tx.db.deleteSubscriptionStmt)
+	return tx.bound.GetSubscriptions()
+}
+
+// book binds `book` and the entries it refers to to the transaction
+func (tx *Tx) book(book Book) Book {
+	tx.bind(&tx.bound.insertBookStmt, tx.db.insertBookStmt)
+	tx.bind(&tx.bound.updateBookStmt, tx.db.updateBookStmt)
+	tx.bind(&tx.bound.deleteBookStmt, tx.db.deleteBookStmt)
+	tx.bind(&tx.bound.dependentsOfBookStmt, tx.db.dependentsOfBookStmt)
+	tx.bind(&tx.bound.restrictedDeleteBookStmt, tx.db.restrictedDeleteBookStmt)
+	book.stmt = tx.bound.insertBookStmt
+	if book.Id != 0 {
+		book.stmt = tx.bound.updateBookStmt
+	}
+	book.deleteStmt = tx.bound.deleteBookStmt
+	book.dependentsStmt = tx.bound.dependentsOfBookStmt
+	book.restrictStmt = tx.bound.restrictedDeleteBookStmt
+	book.Author = tx.author(book.Author)
+	book.Topic = tx.topic(book.Topic)
+	book.Language = tx.language(book.Language)
+	return book
+}
+
+// author binds `author` to the transaction
+func (tx *Tx) author(author Author) Author {
+	tx.bind(&tx.bound.insertAuthorStmt, tx.db.insertAuthorStmt)
+	tx.bind(&tx.bound.updateAuthorStmt, tx.db.updateAuthorStmt)
+	tx.bind(&tx.bound.deleteAuthorStmt, tx.db.deleteAuthorStmt)
+	tx.bind(&tx.bound.dependentsOfAuthorStmt, tx.db.dependentsOfAuthorStmt)
+	tx.bind(&tx.bound.restrictedDeleteAuthorStmt, tx.db.restrictedDeleteAuthorStmt)
+	author.stmt = tx.bound.insertAuthorStmt
+	if author.Id != 0 {
+		author.stmt = tx.bound.updateAuthorStmt
+	}
+	author.deleteStmt = tx.bound.deleteAuthorStmt
+	author.dependentsStmt = tx.bound.dependentsOfAuthorStmt
+	author.restrictStmt = tx.bound.restrictedDeleteAuthorStmt
+	return author
+}
+
+// topic binds `topic` to the transaction
+func (tx *Tx) topic(topic Topic) Topic {
+	tx.bind(&tx.bound.insertTopicStmt, tx.db.insertTopicStmt)
+	tx.bind(&tx.bound.updateTopicStmt, tx.db.updateTopicStmt)
+	tx.bind(&tx.bound.deleteTopicStmt, tx.db.deleteTopicStmt)
+	tx.bind(&tx.bound.dependentsOfTopicStmt, tx.db.dependentsOfTopicStmt)
+	tx.bind(&tx.bound.restrictedDeleteTopicStmt, tx.db.restrictedDeleteTopicStmt)
+	topic.stmt = tx.bound.insertTopicStmt
+	if topic.Id != 0 {
+		topic.stmt = tx.bound.updateTopicStmt
+	}
+	topic.deleteStmt = tx.bound.deleteTopicStmt
+	topic.dependentsStmt = tx.bound.dependentsOfTopicStmt
+	topic.restrictStmt = tx.bound.restrictedDeleteTopicStmt
+	return topic
+}
+
+// language binds `language` to the transaction
+func (tx *Tx) language(language Language) Language {
+	tx.bind(&tx.bound.insertLanguageStmt, tx.db.insertLanguageStmt)
+	tx.bind(&tx.bound.updateLanguageStmt, tx.db.updateLanguageStmt)
+	tx.bind(&tx.bound.deleteLanguageStmt, tx.db.deleteLanguageStmt)
+	tx.bind(&tx.bound.dependentsOfLanguageStmt, tx.db.dependentsOfLanguageStmt)
+	tx.bind(&tx.bound.restrictedDeleteLanguageStmt, tx.db.restrictedDeleteLanguageStmt)
+	language.stmt = tx.bound.insertLanguageStmt
+	if language.Id != 0 {
+		language.stmt = tx.bound.updateLanguageStmt
+	}
+	language.deleteStmt = tx.bound.deleteLanguageStmt
+	language.dependentsStmt = tx.bound.dependentsOfLanguageStmt
+	language.restrictStmt = tx.bound.restrictedDeleteLanguageStmt
+	return language
+}
@@ -0,0 +1,222 @@
+package quote
+
+import (
+	"errors"
+	"testing"
+)
+
+// newQuoteOfNewAuthor creates a quote of a new book of the new author `name`,
+// the book reuses the ISBN of book 1 if `duplicate` is set, so that its
+// commit fails after the author was committed
+func newQuoteOfNewAuthor(t *testing.T, database *Database, tx *Tx, name string, duplicate bool) Quote {
+	existing, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author := tx.NewAuthor()
+	author.Name = name
+	book := tx.NewBook(author, existing.Topic, existing.Language)
+	book.Title = "Book of " + name
+	if duplicate {
+		book.ISBN = existing.ISBN
+	}
+	quote := tx.NewQuote(book)
+	quote.Quote = "Quote of " + name
+	return quote
+}
+
+// hasAuthor tells whether the author `name` is stored in `database`
+func hasAuthor(t *testing.T, database *Database, name string) bool {
+	authors, err := database.GetAuthors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, author := range authors {
+		if author.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithTxCommits(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	var id int
+	// Act
+	err = database.WithTx(func(tx *Tx) (err error) {
+		id, err = newQuoteOfNewAuthor(t, database, tx, "New Author", false).Commit()
+		return
+	})
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote, err := database.GetQuote(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "New Author", quote.Book.Author.Name; actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	err = database.WithTx(func(tx *Tx) (err error) {
+		_, err = newQuoteOfNewAuthor(t, database, tx, "Orphan", true).Commit()
+		return
+	})
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error for a duplicate ISBN but got nil")
+	}
+	if hasAuthor(t, database, "Orphan") {
+		t.Error("Expected the author to be rolled back with the book")
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to be passed on")
+			}
+		}()
+		database.WithTx(func(tx *Tx) error {
+			author := tx.NewAuthor()
+			author.Name = "Panicking"
+			if _, err := author.Commit(); err != nil {
+				return err
+			}
+			panic("work failed")
+		})
+	}()
+	// Assert
+	if hasAuthor(t, database, "Panicking") {
+		t.Error("Expected the author to be rolled back after the panic")
+	}
+}
+
+func TestWithTxBindsBook(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// the book is read outside of the transaction
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	book.Title = "Renamed"
+	errRollback := errors.New("rollback")
+	// Act
+	err = database.WithTx(func(tx *Tx) error {
+		quote := tx.NewQuote(book)
+		quote.Quote = "Quote of a renamed book"
+		if _, err := quote.Commit(); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	// Assert
+	if !errors.Is(err, errRollback) {
+		t.Fatalf(contentError, errRollback, err)
+	}
+	actual, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Title == book.Title {
+		t.Error("Expected the update of the book to be rolled back")
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := 2, len(quotes); actual != expected {
+		t.Errorf(lenError, expected, actual)
+	}
+}
+
+func TestNestedWithTx(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	err = database.WithTx(func(tx *Tx) error {
+		err := tx.WithTx(func(nested *Tx) error {
+			author := nested.NewAuthor()
+			author.Name = "Nested"
+			_, err := author.Commit()
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		_, err = newQuoteOfNewAuthor(t, database, tx, "Outer", true).Commit()
+		return err
+	})
+	// Assert
+	if err == nil {
+		t.Fatal("Expected an error for a duplicate ISBN but got nil")
+	}
+	if hasAuthor(t, database, "Nested") {
+		t.Error("Expected the nested unit of work to be rolled back with the outer")
+	}
+}
+
+func TestWithTxBindsLazily(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	err = database.WithTx(func(tx *Tx) error {
+		if tx.bound.insertDeliveryStmt != nil {
+			t.Error("Expected no statements to be bound before they are used")
+		}
+		_, err := tx.NewDelivery(1, "to@mail.com").Commit()
+		// Assert
+		if tx.bound.insertDeliveryStmt == nil {
+			t.Error("Expected the statement of the delivery to be bound")
+		}
+		if tx.bound.insertQuoteStmt != nil || tx.bound.selectQuotesStmt != nil {
+			t.Error("Expected the unused statements not to be bound")
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
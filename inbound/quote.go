@@ -0,0 +1,241 @@
+package quote
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	db "quote/db"
+	"strconv"
+	"strings"
+)
+
+// usage describes the format of the mails which add a quote
+const usage = `The mail has to contain the text of the quote followed by these lines:
+
+Book: <title>
+Author: <name>
+Page: <number> (optional)
+Topic: <topic> (optional)
+Language: <language> (optional)
+`
+
+// entry is a quote parsed from a mail
+type entry struct {
+	Quote    string
+	Book     string
+	Author   string
+	Page     int
+	Topic    string
+	Language string
+}
+
+// decode returns the content of `r` decoded with the Content-Transfer-Encoding
+// of `header`
+func decode(header mail.Header, r io.Reader) io.Reader {
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	}
+	return r
+}
+
+// textBody returns the first plain text part of a mail with `header` and
+// `body`
+func textBody(header mail.Header, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// mails without a Content-Type are plain text
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return "", errors.New("the mail has no plain text part")
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := textBody(mail.Header(part.Header), part)
+			if err == nil {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", fmt.Errorf("unsupported content type %s", mediaType)
+	}
+	content, err := io.ReadAll(decode(header, body))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// fields are the keys of the lines following the text of a quote
+var fields = map[string]bool{"book": true, "author": true, "page": true,
+	"topic": true, "language": true}
+
+// field returns the key (in lower case) and value of a line "Key: value"
+// with one of the keys of fields
+func field(line string) (key, value string, ok bool) {
+	i := strings.Index(line, ":")
+	if i < 0 {
+		return
+	}
+	key = strings.ToLower(strings.TrimSpace(line[:i]))
+	return key, strings.TrimSpace(line[i+1:]), fields[key]
+}
+
+// parse reads the quote of a mail `body`, which is the text of the quote
+// followed by lines with its Book, Author, Page, Topic and Language. A line
+// with "-- " starts the signature, which is ignored.
+func parse(body string) (e entry, err error) {
+	var text []string
+	quoted := false
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		if line == "-- " {
+			break
+		}
+		key, value, ok := field(line)
+		if !ok {
+			if quoted && strings.TrimSpace(line) != "" {
+				return e, fmt.Errorf("unexpected line %q after the quote", line)
+			}
+			if !quoted {
+				text = append(text, line)
+			}
+			continue
+		}
+		// the text of the quote ends with the first field
+		quoted = true
+		switch key {
+		case "book":
+			e.Book = value
+		case "author":
+			e.Author = value
+		case "topic":
+			e.Topic = value
+		case "language":
+			e.Language = value
+		case "page":
+			if e.Page, err = strconv.Atoi(value); err != nil || e.Page < 0 {
+				return e, fmt.Errorf("invalid page %q", value)
+			}
+		}
+	}
+	e.Quote = strings.TrimSpace(strings.Join(text, "\n"))
+	switch {
+	case e.Quote == "":
+		err = errors.New("missing text of the quote")
+	case e.Book == "":
+		err = errors.New("missing Book")
+	case e.Author == "":
+		err = errors.New("missing Author")
+	}
+	return
+}
+
+// commit adds the quote `e` to the Database, reusing its book, author, topic
+// and language if they exist already. The new entries are stored in a single
+// transaction, so that a failing quote leaves no book behind.
+func (s Server) commit(e entry) (quote db.Quote, err error) {
+	book, err := s.book(e)
+	if err != nil {
+		return
+	}
+	err = s.Database.WithTx(func(tx *db.Tx) (err error) {
+		quote = tx.NewQuote(book)
+		quote.Quote = e.Quote
+		quote.Page = e.Page
+		quote.Id, err = quote.Commit()
+		return
+	})
+	if err != nil {
+		return
+	}
+	return s.Database.GetQuote(quote.Id)
+}
+
+// book returns the book of the quote `e`, which is a new book if it does not
+// exist yet
+func (s Server) book(e entry) (book db.Book, err error) {
+	books, err := s.Database.GetBooks()
+	if err != nil {
+		return
+	}
+	found := false
+	for _, candidate := range books {
+		if strings.EqualFold(candidate.Title, e.Book) &&
+			strings.EqualFold(candidate.Author.Name, e.Author) {
+			book, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		book, err = s.newBook(e)
+	}
+	return
+}
+
+// newBook creates the book of the quote `e`, which is stored together with
+// its quote
+func (s Server) newBook(e entry) (book db.Book, err error) {
+	topicName, languageName := e.Topic, e.Language
+	if topicName == "" {
+		topicName = s.Config.Topic
+	}
+	if languageName == "" {
+		languageName = s.Config.Language
+	}
+	if topicName == "" {
+		return book, errors.New("missing Topic of the new book")
+	}
+	if languageName == "" {
+		return book, errors.New("missing Language of the new book")
+	}
+	author := s.Database.NewAuthor()
+	author.Name = e.Author
+	authors, err := s.Database.GetAuthors()
+	if err != nil {
+		return
+	}
+	for _, candidate := range authors {
+		if strings.EqualFold(candidate.Name, e.Author) {
+			author = candidate
+		}
+	}
+	topic := s.Database.NewTopic()
+	topic.Topic = topicName
+	topics, err := s.Database.GetTopics()
+	if err != nil {
+		return
+	}
+	for _, candidate := range topics {
+		if strings.EqualFold(candidate.Topic, topicName) {
+			topic = candidate
+		}
+	}
+	language := s.Database.NewLanguage()
+	language.Language = languageName
+	languages, err := s.Database.GetLanguages()
+	if err != nil {
+		return
+	}
+	for _, candidate := range languages {
+		if strings.EqualFold(candidate.Language, languageName) {
+			language = candidate
+		}
+	}
+	book = s.Database.NewBook(author, topic, language)
+	book.Title = e.Book
+	return
+}
@@ -0,0 +1,148 @@
+package quote
+
+import (
+	"io"
+	"net/mail"
+	"os"
+	db "quote/db"
+	"strings"
+	"testing"
+)
+
+const (
+	testSource   = "./../test.sqlite"
+	testDatabase = "./cur_test.sqlite"
+	contentError = "content had not the expected value\nexpected: %v\nactual: %v\n"
+)
+
+func initDatabase(t *testing.T) {
+	source, err := os.Open(testSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+
+	destination, err := os.Create(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer destination.Close()
+	io.Copy(destination, source)
+}
+
+func TestParse(t *testing.T) {
+	// Arrange
+	body := "To be, or not to be:\r\nthat is the question.\r\n\r\nBook: Hamlet\r\n" +
+		"author: William Shakespeare\r\nPage: 42\r\n\r\n-- \r\nSent from my phone\r\n"
+	expected := entry{Quote: "To be, or not to be:\nthat is the question.",
+		Book: "Hamlet", Author: "William Shakespeare", Page: 42}
+	// Act
+	actual, err := parse(body)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	// Arrange
+	bodies := []string{
+		"Book: Hamlet\nAuthor: William Shakespeare\n",
+		"To be\nAuthor: William Shakespeare\n",
+		"To be\nBook: Hamlet\n",
+		"To be\nBook: Hamlet\nAuthor: William Shakespeare\nPage: many\n",
+		"To be\nBook: Hamlet\nOr not to be\nAuthor: William Shakespeare\n",
+	}
+	for _, body := range bodies {
+		// Act
+		_, err := parse(body)
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %q but got nil", body)
+		}
+	}
+}
+
+func TestTextBody(t *testing.T) {
+	// Arrange
+	data := "Content-Type: multipart/alternative; boundary=b\r\n\r\n" +
+		"--b\r\nContent-Type: text/html\r\n\r\n<p>html</p>\r\n" +
+		"--b\r\nContent-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n=C3=9Cber alles\r\n--b--\r\n"
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	body, err := textBody(message.Header, message.Body)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Über alles"; body != expected {
+		t.Errorf(contentError, expected, body)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	server := Server{Config: Config{Topic: "Drama", Language: "Language1"}, Database: database}
+	entries := []entry{
+		// existing book of the fixture
+		{Quote: "Known", Book: "Book1", Author: "Author1", Page: 7},
+		// new book of an existing author with a new topic
+		{Quote: "Unknown", Book: "Hamlet", Author: "author2"},
+	}
+	for _, e := range entries {
+		// Act
+		quote, err := server.commit(e)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quote.Quote != e.Quote || quote.Page != e.Page ||
+			quote.Book.Title != e.Book || !strings.EqualFold(quote.Book.Author.Name, e.Author) {
+			t.Errorf(contentError, e, quote)
+		}
+	}
+	books, err := database.GetBooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors, err := database.GetAuthors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(books) != 3 || len(authors) != 2 {
+		t.Errorf(contentError, "3 books of 2 authors", books)
+	}
+	if topic := books[2].Topic.Topic; topic != "Drama" {
+		t.Errorf(contentError, "Drama", topic)
+	}
+}
+
+func TestCommitWithoutTopic(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	server := Server{Database: database}
+	// Act
+	_, err = server.commit(entry{Quote: "Unknown", Book: "Hamlet", Author: "Author1"})
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for a new book without a topic but got nil")
+	}
+}
@@ -0,0 +1,367 @@
+package quote
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/mail"
+	db "quote/db"
+	secret "quote/secret"
+	"strings"
+	"time"
+)
+
+// defaultMaxSize is the default maximum size of a received mail in bytes
+const defaultMaxSize = 1 << 20
+
+// commandTimeout is the time a client has to send its next command
+const commandTimeout = 5 * time.Minute
+
+// maxLineLength is the maximum length of a command or text line in octets
+// including its line break (RFC 5321 section 4.5.3.1)
+const maxLineLength = 1000
+
+// Config of the SMTP listener receiving the quotes
+type Config struct {
+	// Address the listener is bound to, e.g. :2525, no listener is started
+	// if it is empty
+	Address string
+	// Domain of the listener used in its greeting, defaults to localhost
+	Domain string
+	// Senders are the addresses from which quotes are accepted, both the
+	// envelope sender and the From: header of a mail have to be one of them
+	Senders []string
+	// Token is the shared secret the subject of a mail has to contain, as
+	// the sender addresses can be forged. No mails are accepted without it.
+	Token secret.Secret
+	// Topic and Language of new books, unless a mail names them with the
+	// Topic: and Language: lines
+	Topic    string
+	Language string
+	// MaxSize is the maximum size of a mail in bytes, defaults to 1 MiB
+	MaxSize int
+}
+
+// Replier sends the answer to a received mail to its sender
+type Replier func(recipient, subject, text string) error
+
+// Server is a minimal SMTP server (RFC 5321) adding the quotes of the mails
+// from allowed senders to the Database. The sender is answered with a
+// confirmation or the reason why the quote could not be added.
+type Server struct {
+	Config   Config
+	Database *db.Database
+	Reply    Replier
+}
+
+var errNoToken = errors.New("no token is configured for inbound mails")
+
+// ListenAndServe listens on the configured address and serves the clients
+func (s Server) ListenAndServe() error {
+	if s.Config.Token.Value() == "" {
+		return errNoToken
+	}
+	listener, err := net.Listen("tcp", s.Config.Address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve accepts the clients of `listener` until it is closed
+func (s Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// allowed reports whether mails from `address` are accepted
+func (s Server) allowed(address string) bool {
+	for _, sender := range s.Config.Senders {
+		if strings.EqualFold(sender, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate checks that the mail `data` is from an allowed sender in its
+// From: header and contains the token in its subject
+func (s Server) authenticate(data []byte) error {
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(message.Header.Get("From"))
+	if err != nil || !s.allowed(from.Address) {
+		return errors.New("sender not allowed")
+	}
+	token := []byte(s.Config.Token.Value())
+	if len(token) > 0 {
+		for _, word := range strings.Fields(message.Header.Get("Subject")) {
+			if subtle.ConstantTimeCompare([]byte(word), token) == 1 {
+				return nil
+			}
+		}
+	}
+	return errors.New("missing token")
+}
+
+// session is the state of a client connection
+type session struct {
+	conn       net.Conn
+	reader     *bufio.Reader
+	greeted    bool
+	sender     string
+	recipients int
+}
+
+func (c *session) reply(code int, text string) {
+	fmt.Fprintf(c.conn, "%d %s\r\n", code, text)
+}
+
+func (c *session) reset() {
+	c.sender = ""
+	c.recipients = 0
+}
+
+// path returns the address of the reverse or forward path of a MAIL or RCPT
+// command argument, e.g. FROM:<me@mail.com> SIZE=42
+func path(argument, prefix string) (string, bool) {
+	if len(argument) < len(prefix) || !strings.EqualFold(argument[:len(prefix)], prefix) {
+		return "", false
+	}
+	argument = strings.TrimSpace(argument[len(prefix):])
+	if !strings.HasPrefix(argument, "<") {
+		return "", false
+	}
+	end := strings.Index(argument, ">")
+	if end < 0 {
+		return "", false
+	}
+	return argument[1:end], true
+}
+
+func (s Server) handle(conn net.Conn) {
+	defer conn.Close()
+	domain := s.Config.Domain
+	if domain == "" {
+		domain = "localhost"
+	}
+	maxSize := s.Config.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxSize
+	}
+	c := &session{conn: conn, reader: bufio.NewReader(conn)}
+	c.reply(220, domain+" ESMTP quote-reminder")
+	for {
+		conn.SetDeadline(time.Now().Add(commandTimeout))
+		line, err := readLine(c.reader)
+		if errors.Is(err, errLineTooLong) {
+			c.reply(500, "Line too long")
+			continue
+		}
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, argument := line, ""
+		if i := strings.Index(line, " "); i >= 0 {
+			verb, argument = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			c.greeted = true
+			c.reset()
+			c.reply(250, domain)
+		case "EHLO":
+			c.greeted = true
+			c.reset()
+			fmt.Fprintf(conn, "250-%s\r\n250-8BITMIME\r\n250 SIZE %d\r\n", domain, maxSize)
+		case "MAIL":
+			address, ok := path(argument, "FROM:")
+			switch {
+			case !c.greeted:
+				c.reply(503, "Send HELO or EHLO first")
+			case c.sender != "":
+				c.reply(503, "Sender already specified")
+			case !ok:
+				c.reply(501, "Syntax: MAIL FROM:<address>")
+			case !s.allowed(address):
+				c.reply(550, "Sender not allowed")
+			default:
+				c.sender = address
+				c.reply(250, "OK")
+			}
+		case "RCPT":
+			_, ok := path(argument, "TO:")
+			switch {
+			case c.sender == "":
+				c.reply(503, "Send MAIL first")
+			case !ok:
+				c.reply(501, "Syntax: RCPT TO:<address>")
+			default:
+				c.recipients += 1
+				c.reply(250, "OK")
+			}
+		case "DATA":
+			if c.recipients == 0 {
+				c.reply(503, "Send RCPT first")
+				continue
+			}
+			c.reply(354, "End data with <CR><LF>.<CR><LF>")
+			data, err := readData(c.reader, maxSize)
+			if errors.Is(err, errTooLarge) {
+				c.reply(552, "Message exceeds the maximum size")
+				c.reset()
+				continue
+			}
+			if errors.Is(err, errLineTooLong) {
+				c.reply(552, "Line too long")
+				c.reset()
+				continue
+			}
+			if err != nil {
+				return
+			}
+			// unauthenticated mails are not answered, as their sender
+			// may be forged
+			if err = s.authenticate(data); err != nil {
+				log.Printf("mail from %s rejected: %v", c.sender, err)
+				c.reply(550, "Mail not accepted")
+				c.reset()
+				continue
+			}
+			c.reply(250, "OK")
+			s.receive(c.sender, data)
+			c.reset()
+		case "RSET":
+			c.reset()
+			c.reply(250, "OK")
+		case "NOOP":
+			c.reply(250, "OK")
+		case "VRFY":
+			c.reply(252, "Cannot verify the user")
+		case "QUIT":
+			c.reply(221, "Bye")
+			return
+		default:
+			c.reply(502, "Command not implemented")
+		}
+	}
+}
+
+var (
+	errTooLarge    = errors.New("message exceeds the maximum size")
+	errLineTooLong = errors.New("line too long")
+)
+
+// readLine reads a line of at most maxLineLength octets. The rest of a longer
+// line is discarded without buffering it and errLineTooLong is returned.
+func readLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if !tooLong && len(line)+len(chunk) <= maxLineLength {
+			line = append(line, chunk...)
+		} else {
+			tooLong = true
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if tooLong {
+			return "", errLineTooLong
+		}
+		return string(line), nil
+	}
+}
+
+// readData reads the content of a DATA command up to the terminating line
+// with a single dot, removing the dot-stuffing
+func readData(reader *bufio.Reader, maxSize int) ([]byte, error) {
+	var data bytes.Buffer
+	tooLarge, tooLong := false, false
+	for {
+		line, err := readLine(reader)
+		if errors.Is(err, errLineTooLong) {
+			// the rest of the message has to be read anyway
+			tooLong = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == ".\r\n" || line == ".\n" {
+			break
+		}
+		line = strings.TrimPrefix(line, ".")
+		if data.Len()+len(line) > maxSize {
+			// the rest of the message has to be read anyway
+			tooLarge = true
+			continue
+		}
+		data.WriteString(line)
+	}
+	if tooLong {
+		return nil, errLineTooLong
+	}
+	if tooLarge {
+		return nil, errTooLarge
+	}
+	return data.Bytes(), nil
+}
+
+// receive adds the quote of the mail `data` from `sender` and answers them
+func (s Server) receive(sender string, data []byte) {
+	subject := "Quote added"
+	var text string
+	quote, err := s.addQuote(data)
+	if err != nil {
+		subject = "Quote not added"
+		text = fmt.Sprintf("Your quote could not be added: %v\n\n%s", err, usage)
+		log.Printf("quote from %s not added: %v", sender, err)
+	} else {
+		text = fmt.Sprintf("Your quote was added with the id %d:\n\n'%s' from '%s' by %s, page %d\n",
+			quote.Id, quote.Quote, quote.Book.Title, quote.Book.Author.Name, quote.Page)
+	}
+	if s.Reply == nil {
+		return
+	}
+	if err := s.Reply(sender, subject, text); err != nil {
+		log.Printf("reply to %s failed: %v", sender, err)
+	}
+}
+
+// addQuote parses the quote of the mail `data` and adds it to the Database
+func (s Server) addQuote(data []byte) (quote db.Quote, err error) {
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	body, err := textBody(message.Header, message.Body)
+	if err != nil {
+		return
+	}
+	entry, err := parse(body)
+	if err != nil {
+		return
+	}
+	return s.commit(entry)
+}
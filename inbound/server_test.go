@@ -0,0 +1,195 @@
+package quote
+
+import (
+	"net"
+	"net/smtp"
+	db "quote/db"
+	secret "quote/secret"
+	"strings"
+	"testing"
+)
+
+// reply is an answer of the Server
+type reply struct {
+	recipient, subject, text string
+}
+
+// startServer serves a Server with the allowed sender from@mail.com and the
+// token s3cret on a random local port, its answers are sent to the returned
+// channel
+func startServer(t *testing.T, database *db.Database) (string, <-chan reply) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replies := make(chan reply, 1)
+	server := Server{
+		Config: Config{
+			Senders:  []string{"From@mail.com"},
+			Token:    secret.New("s3cret"),
+			Language: "Language1",
+			Topic:    "Topic1",
+			MaxSize:  1024,
+		},
+		Database: database,
+		Reply: func(recipient, subject, text string) error {
+			replies <- reply{recipient, subject, text}
+			return nil
+		},
+	}
+	go server.Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String(), replies
+}
+
+func TestReceiveQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	address, replies := startServer(t, database)
+	message := "From: Me <from@mail.com>\r\nTo: quotes@mail.com\r\nSubject: quote s3cret\r\n\r\n" +
+		".A quote starting with a dot\r\n\r\nBook: Book2\r\nAuthor: Author2\r\nPage: 12\r\n"
+	// Act
+	err = smtp.SendMail(address, nil, "from@mail.com", []string{"quotes@mail.com"}, []byte(message))
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer := <-replies
+	if answer.recipient != "from@mail.com" || answer.subject != "Quote added" {
+		t.Errorf(contentError, "confirmation to from@mail.com", answer)
+	}
+	quote, err := database.GetQuote(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Quote != ".A quote starting with a dot" || quote.Book.Id != 2 || quote.Page != 12 {
+		t.Errorf(contentError, message, quote)
+	}
+}
+
+func TestReceiveInvalidQuote(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	address, replies := startServer(t, database)
+	message := "From: from@mail.com\r\nSubject: s3cret\r\n\r\nA quote without its book\r\n"
+	// Act
+	err = smtp.SendMail(address, nil, "from@mail.com", []string{"quotes@mail.com"}, []byte(message))
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer := <-replies
+	if answer.subject != "Quote not added" || !strings.Contains(answer.text, "missing Book") {
+		t.Errorf(contentError, "error reply", answer)
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 {
+		t.Errorf(contentError, 2, len(quotes))
+	}
+}
+
+func TestRejectSender(t *testing.T) {
+	// Arrange
+	address, _ := startServer(t, nil)
+	// Act
+	err := smtp.SendMail(address, nil, "spam@mail.com", []string{"quotes@mail.com"}, []byte("Subject: spam\r\n\r\nspam\r\n"))
+	// Assert
+	if err == nil || !strings.HasPrefix(err.Error(), "550") {
+		t.Errorf(contentError, "550 Sender not allowed", err)
+	}
+}
+
+func TestRejectUnauthenticated(t *testing.T) {
+	// Arrange
+	address, replies := startServer(t, nil)
+	messages := map[string]string{
+		"forged From: header":  "From: spam@mail.com\r\nSubject: s3cret\r\n\r\nspam\r\n",
+		"missing From: header": "Subject: s3cret\r\n\r\nspam\r\n",
+		"missing token":        "From: from@mail.com\r\nSubject: quote\r\n\r\nspam\r\n",
+		"wrong token":          "From: from@mail.com\r\nSubject: s3cre\r\n\r\nspam\r\n",
+	}
+	for name, message := range messages {
+		// Act
+		err := smtp.SendMail(address, nil, "from@mail.com", []string{"quotes@mail.com"}, []byte(message))
+		// Assert
+		if err == nil || !strings.HasPrefix(err.Error(), "550") {
+			t.Errorf(contentError, name+" rejected with 550", err)
+		}
+	}
+	if len(replies) != 0 {
+		t.Errorf(contentError, "no replies", len(replies))
+	}
+}
+
+func TestRejectLargeMessage(t *testing.T) {
+	// Arrange
+	address, _ := startServer(t, nil)
+	message := "From: from@mail.com\r\nSubject: s3cret\r\n\r\n" + strings.Repeat("quote ", 1024) + "\r\n"
+	// Act
+	err := smtp.SendMail(address, nil, "from@mail.com", []string{"quotes@mail.com"}, []byte(message))
+	// Assert
+	if err == nil || !strings.HasPrefix(err.Error(), "552") {
+		t.Errorf(contentError, "552 Message exceeds the maximum size", err)
+	}
+}
+
+func TestRejectLongLine(t *testing.T) {
+	// Arrange
+	address, _ := startServer(t, nil)
+	client, err := smtp.Dial(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	// Act
+	err = client.Verify(strings.Repeat("x", 2000) + "@mail.com")
+	// Assert
+	if err == nil || !strings.HasPrefix(err.Error(), "500") {
+		t.Errorf(contentError, "500 Line too long", err)
+	}
+	if err = client.Noop(); err != nil {
+		t.Error(err)
+	}
+	// Act
+	message := "From: from@mail.com\r\nSubject: s3cret\r\n\r\n" + strings.Repeat("x", 999) + "\r\n"
+	err = smtp.SendMail(address, nil, "from@mail.com", []string{"quotes@mail.com"}, []byte(message))
+	// Assert
+	if err == nil || !strings.HasPrefix(err.Error(), "552") {
+		t.Errorf(contentError, "552 Line too long", err)
+	}
+}
+
+func TestCommandSequence(t *testing.T) {
+	// Arrange
+	address, _ := startServer(t, nil)
+	client, err := smtp.Dial(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	// Act
+	err = client.Rcpt("quotes@mail.com")
+	// Assert
+	if err == nil || !strings.HasPrefix(err.Error(), "503") {
+		t.Errorf(contentError, "503 Send MAIL first", err)
+	}
+	if err = client.Noop(); err != nil {
+		t.Error(err)
+	}
+	if err = client.Quit(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,85 @@
+package quote
+
+import (
+	"fmt"
+	"math"
+	db "quote/db"
+	"strings"
+	"unicode/utf8"
+)
+
+// Units of the length budget of a reminder and the length of its quotes
+const (
+	CharsUnit = "chars"
+	WordsUnit = "words"
+)
+
+// unlimited is the count of quotes selected for a reminder with a budget,
+// which is limited by the budget instead
+const unlimited = math.MaxInt32
+
+// lengthUnit returns the unit of Budget, MinLength and MaxLength, which
+// defaults to CharsUnit
+func (c Config) lengthUnit() (string, error) {
+	switch unit := strings.ToLower(c.LengthUnit); unit {
+	case "", CharsUnit:
+		return CharsUnit, nil
+	case WordsUnit:
+		return unit, nil
+	}
+	return "", fmt.Errorf("unknown length unit %q", c.LengthUnit)
+}
+
+// length returns the length of `quote` in characters or words
+func (c Config) length(quote db.Quote) int {
+	if unit, _ := c.lengthUnit(); unit == WordsUnit {
+		return len(strings.Fields(quote.Quote))
+	}
+	return utf8.RuneCountInString(quote.Quote)
+}
+
+// fits reports whether the length of `quote` is within MinLength and
+// MaxLength and does not exceed the Budget on its own. A quote exceeding the
+// budget could never be packed, so it must not hold up a cycle of the
+// delivery history (see unrepeated).
+func (c Config) fits(quote db.Quote) bool {
+	length := c.length(quote)
+	return length >= c.MinLength && (c.MaxLength <= 0 || length <= c.MaxLength) &&
+		(c.Budget <= 0 || length <= c.Budget)
+}
+
+// pack returns the quotes of `quotes` in their order which fit into the
+// budget together, skipping the ones which would exceed it. All quotes are
+// returned without a budget.
+func (c Config) pack(quotes []db.Quote) (packed []db.Quote) {
+	if c.Budget <= 0 {
+		return quotes
+	}
+	left := c.Budget
+	for _, quote := range quotes {
+		if length := c.length(quote); length <= left {
+			packed = append(packed, quote)
+			left -= length
+		}
+	}
+	return
+}
+
+// packSeries returns the first quotes of `quotes` which fit into the budget
+// together, but at least one quote so that a series does not get stuck on a
+// quote exceeding the budget
+func (c Config) packSeries(quotes []db.Quote) []db.Quote {
+	if c.Budget <= 0 {
+		return quotes
+	}
+	left := c.Budget
+	for i, quote := range quotes {
+		if left -= c.length(quote); left < 0 {
+			if i == 0 {
+				i = 1
+			}
+			return quotes[:i]
+		}
+	}
+	return quotes
+}
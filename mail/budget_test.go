@@ -0,0 +1,175 @@
+package quote
+
+import (
+	db "quote/db"
+	"sort"
+	"testing"
+)
+
+func lengthPool() []db.Quote {
+	return []db.Quote{
+		{Id: 1, Quote: "A rather long quote of eight words here."},
+		{Id: 2, Quote: "Short one."},
+		{Id: 3, Quote: "Four words are enough."},
+		{Id: 4, Quote: "Über."},
+	}
+}
+
+func assertIds(t *testing.T, expected []int, quotes []db.Quote) {
+	if len(quotes) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(quotes))
+	}
+	for i, quote := range quotes {
+		if quote.Id != expected[i] {
+			t.Errorf(contentError, expected[i], quote.Id)
+		}
+	}
+}
+
+func TestPack(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		config   Config
+		expected []int
+	}{
+		{Config{}, []int{1, 2, 3, 4}},
+		// the first quote exceeds the budget and is skipped
+		{Config{Budget: 35}, []int{2, 3}},
+		{Config{Budget: 40}, []int{1}},
+		{Config{Budget: 45}, []int{1, 4}},
+		{Config{Budget: 7, LengthUnit: WordsUnit}, []int{2, 3, 4}},
+		{Config{Budget: 10, LengthUnit: WordsUnit}, []int{1, 2}},
+	}
+	for _, c := range cases {
+		// Act
+		packed := c.config.pack(lengthPool())
+		// Assert
+		assertIds(t, c.expected, packed)
+	}
+}
+
+func TestPackSeries(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		config   Config
+		expected []int
+	}{
+		{Config{}, []int{1, 2, 3, 4}},
+		// at least one quote is selected
+		{Config{Budget: 10}, []int{1}},
+		{Config{Budget: 50}, []int{1, 2}},
+		{Config{Budget: 10, LengthUnit: WordsUnit}, []int{1, 2}},
+	}
+	for _, c := range cases {
+		// Act
+		packed := c.config.packSeries(lengthPool())
+		// Assert
+		assertIds(t, c.expected, packed)
+	}
+}
+
+func TestFits(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		config   Config
+		expected []int
+	}{
+		{Config{MinLength: 10}, []int{1, 2, 3}},
+		{Config{MaxLength: 10}, []int{2, 4}},
+		{Config{MinLength: 2, MaxLength: 4, LengthUnit: WordsUnit}, []int{2, 3}},
+		// quotes exceeding the budget on their own never fit
+		{Config{Budget: 22}, []int{2, 3, 4}},
+	}
+	for _, c := range cases {
+		var fitting []db.Quote
+		// Act
+		for _, quote := range lengthPool() {
+			if c.config.fits(quote) {
+				fitting = append(fitting, quote)
+			}
+		}
+		// Assert
+		assertIds(t, c.expected, fitting)
+	}
+}
+
+func TestSelectQuotesBudget(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := database.NewQuote(book)
+	quote.Quote = "A quote which is too long for the budget"
+	if _, err = quote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// Quote1 and Quote2 have a length of 6 characters
+	config := Config{Count: 1, Budget: 12, Receiver: []string{"to@mail.com"}}
+	// Act
+	selection, err := config.selectQuotes(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the random strategy selects the quotes in random order
+	sort.Slice(selection, func(i, j int) bool {
+		return selection[i].Id < selection[j].Id
+	})
+	assertIds(t, []int{1, 2}, selection)
+}
+
+func TestSelectQuotesOversizedUndelivered(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := database.NewQuote(book)
+	quote.Quote = "A quote which is too long for the budget"
+	if _, err = quote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all quotes but the oversized one have been delivered
+	config := Config{Count: 1, Budget: 12, Receiver: []string{"to@mail.com"}}
+	if err = recordDeliveries(database, config.Receiver, quotes[:2]); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	selection, err := config.selectQuotes(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(selection, func(i, j int) bool {
+		return selection[i].Id < selection[j].Id
+	})
+	assertIds(t, []int{1, 2}, selection)
+}
+
+func TestSelectQuotesUnknownLengthUnit(t *testing.T) {
+	// Arrange
+	config := Config{Budget: 12, LengthUnit: "pages"}
+	// Act
+	_, err := config.selectQuotes(nil)
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for an unknown length unit but got nil")
+	}
+}
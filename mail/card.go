@@ -0,0 +1,273 @@
+package quote
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	db "quote/db"
+	"strconv"
+	"strings"
+)
+
+// fontData is a 5x7 bitmap font covering ASCII and the most common accented
+// letters, so that the cards are rendered without any font installed
+//
+//go:embed fonts/5x7.txt
+var fontData string
+
+// Dimensions of the glyphs of the font in pixels
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// Layout of a quote card, the scales multiply the glyph pixels
+const (
+	defaultCardWidth      = 800
+	defaultCardBackground = "#f5f0e6"
+	defaultCardForeground = "#333333"
+	cardPadding           = 40
+	quoteScale            = 3
+	attributionScale      = 2
+	// letterSpacing and lineSpacing are unscaled pixels
+	letterSpacing = 1
+	lineSpacing   = 3
+)
+
+// glyph holds the rows of a character of the font, the leftmost pixel of a
+// row is its highest bit
+type glyph [glyphHeight]uint8
+
+var font = parseFont(fontData)
+
+// typography replaces the characters which are missing in the font by
+// similar ones
+var typography = strings.NewReplacer(
+	"“", "\"", "”", "\"", "„", "\"", "«", "\"", "»", "\"",
+	"‘", "'", "’", "'", "‚", "'",
+	"–", "-", "—", "-", "…", "...", "\u00a0", " ",
+)
+
+// parseFont parses the glyphs of `data`, which is a code point (U+XXXX)
+// followed by a row of the glyph per line, where # is a set pixel. It panics
+// on malformed data, as the font is embedded.
+func parseFont(data string) map[rune]glyph {
+	glyphs := make(map[rune]glyph)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		code, err := strconv.ParseUint(strings.TrimPrefix(line, "U+"), 16, 32)
+		if err != nil || !strings.HasPrefix(line, "U+") {
+			panic(fmt.Sprintf("invalid code point %q in font", line))
+		}
+		var g glyph
+		for row := range g {
+			if !scanner.Scan() || len(scanner.Text()) != glyphWidth {
+				panic(fmt.Sprintf("incomplete glyph %q in font", line))
+			}
+			for _, pixel := range scanner.Text() {
+				g[row] <<= 1
+				if pixel == '#' {
+					g[row] |= 1
+				}
+			}
+		}
+		glyphs[rune(code)] = g
+	}
+	return glyphs
+}
+
+// printable replaces the characters of `text` which are missing in the font
+func printable(text string) string {
+	return strings.Map(func(r rune) rune {
+		if _, ok := font[r]; ok {
+			return r
+		}
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		return '?'
+	}, typography.Replace(text))
+}
+
+// wrap breaks `text` into lines of at most `columns` characters at spaces,
+// words which are longer are split
+func wrap(text string, columns int) (lines []string) {
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		runes := []rune(word)
+		if len(line) > 0 && len(line)+1+len(runes) > columns {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		for len(line) == 0 && len(runes) > columns {
+			lines = append(lines, string(runes[:columns]))
+			runes = runes[columns:]
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, runes...)
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return
+}
+
+// parseColor parses a hex color like #rrggbb or #rgb
+func parseColor(hex string) (color.RGBA, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", hex)
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, nil
+}
+
+// CardOptions configure the appearance of a quote card
+type CardOptions struct {
+	// Width of the card in pixels, the height follows from the text
+	Width      int
+	Background color.Color
+	Foreground color.Color
+}
+
+// textBlock is a wrapped text of a card drawn at a scale
+type textBlock struct {
+	lines []string
+	scale int
+}
+
+func (b textBlock) lineHeight() int {
+	return (glyphHeight + lineSpacing) * b.scale
+}
+
+// QuoteCard renders `quote` as an image, which contains the quote wrapped to
+// the width of the card followed by the title and author of its book. Options
+// which are not set default like the ones of the configuration.
+func QuoteCard(quote db.Quote, options CardOptions) *image.RGBA {
+	width := options.Width
+	if width <= 0 {
+		width = defaultCardWidth
+	}
+	// the default colors are valid, so their errors can be ignored
+	if options.Background == nil {
+		options.Background, _ = parseColor(defaultCardBackground)
+	}
+	if options.Foreground == nil {
+		options.Foreground, _ = parseColor(defaultCardForeground)
+	}
+	columns := func(scale int) int {
+		columns := (width - 2*cardPadding) / ((glyphWidth + letterSpacing) * scale)
+		if columns < 1 {
+			return 1
+		}
+		return columns
+	}
+	attribution := "by " + quote.Book.Author.Name
+	if quote.Page != 0 {
+		attribution += fmt.Sprintf(", page %d", quote.Page)
+	}
+	blocks := []textBlock{
+		{wrap(printable("\""+quote.Quote+"\""), columns(quoteScale)), quoteScale},
+		{wrap(printable("- "+quote.Book.Title), columns(attributionScale)), attributionScale},
+		{wrap(printable(attribution), columns(attributionScale)), attributionScale},
+	}
+	// the quote is separated from its attribution by an empty line
+	height := 2*cardPadding + blocks[1].lineHeight()
+	for _, block := range blocks {
+		height += len(block.lines) * block.lineHeight()
+	}
+	card := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(card, card.Bounds(), image.NewUniform(options.Background), image.Point{}, draw.Src)
+	ink := image.NewUniform(options.Foreground)
+	y := cardPadding
+	for i, block := range blocks {
+		if i == 1 {
+			y += block.lineHeight()
+		}
+		for _, line := range block.lines {
+			drawText(card, ink, line, cardPadding, y, block.scale)
+			y += block.lineHeight()
+		}
+	}
+	return card
+}
+
+// drawText draws `text` with its top left corner at `x` and `y`
+func drawText(dst draw.Image, ink image.Image, text string, x, y, scale int) {
+	for _, r := range text {
+		g := font[r]
+		for row, bits := range g {
+			for column := 0; column < glyphWidth; column++ {
+				if bits&(1<<(glyphWidth-1-column)) == 0 {
+					continue
+				}
+				pixel := image.Rect(x+column*scale, y+row*scale, x+(column+1)*scale, y+(row+1)*scale)
+				draw.Draw(dst, pixel, ink, image.Point{}, draw.Src)
+			}
+		}
+		x += (glyphWidth + letterSpacing) * scale
+	}
+}
+
+// cardOptions returns the card settings of `c` with their defaults
+func (c Config) cardOptions() (options CardOptions, err error) {
+	background, foreground := c.CardBackground, c.CardForeground
+	if background == "" {
+		background = defaultCardBackground
+	}
+	if foreground == "" {
+		foreground = defaultCardForeground
+	}
+	if options.Background, err = parseColor(background); err != nil {
+		return
+	}
+	if options.Foreground, err = parseColor(foreground); err != nil {
+		return
+	}
+	options.Width = c.CardWidth
+	return
+}
+
+// Card renders the quote card of `quote` as PNG image
+func (c Config) Card(quote db.Quote) ([]byte, error) {
+	options, err := c.cardOptions()
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	if err = png.Encode(&buffer, QuoteCard(quote, options)); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// cards renders the quote cards of `quotes` as attachments, which show the
+// quiz version of `clozes` if it is not nil, so that they do not give away
+// the answers of a quiz
+func (c Config) cards(quotes []db.Quote, clozes map[int]string) (cards []attachment, err error) {
+	for _, quote := range quotes {
+		if clozes != nil {
+			quote.Quote = clozes[quote.Id]
+		}
+		var content []byte
+		if content, err = c.Card(quote); err != nil {
+			return
+		}
+		cards = append(cards, attachment{fmt.Sprintf("quote-%d.png", quote.Id), "image/png", content})
+	}
+	return
+}
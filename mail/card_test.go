@@ -0,0 +1,253 @@
+package quote
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	db "quote/db"
+	"strings"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		text     string
+		columns  int
+		expected []string
+	}{
+		{"The sun is up, the day is long.", 10, []string{"The sun is", "up, the", "day is", "long."}},
+		{"  spaces   are  collapsed ", 20, []string{"spaces are collapsed"}},
+		// words which are too long are split
+		{"a Donaudampfschiff", 6, []string{"a", "Donaud", "ampfsc", "hiff"}},
+		{"", 10, nil},
+	}
+	for _, c := range cases {
+		// Act
+		actual := wrap(c.text, c.columns)
+		// Assert
+		if len(actual) != len(c.expected) {
+			t.Fatalf(lenError, len(c.expected), len(actual))
+		}
+		for i, line := range actual {
+			if line != c.expected[i] {
+				t.Errorf(contentError, c.expected[i], line)
+			}
+		}
+	}
+}
+
+func TestPrintable(t *testing.T) {
+	// Arrange
+	text := "„Über“ – ’tis… 日本"
+	expected := "\"Über\" - 'tis... ??"
+	// Act
+	actual := printable(text)
+	// Assert
+	if actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	// Arrange
+	cases := map[string]color.RGBA{
+		"#f5f0e6": {0xf5, 0xf0, 0xe6, 0xff},
+		"#FFF":    {0xff, 0xff, 0xff, 0xff},
+		"102030":  {0x10, 0x20, 0x30, 0xff},
+	}
+	for hex, expected := range cases {
+		// Act
+		actual, err := parseColor(hex)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != expected {
+			t.Errorf(contentError, expected, actual)
+		}
+	}
+	for _, hex := range []string{"", "#12345", "#gggggg", "#1234567"} {
+		if _, err := parseColor(hex); err == nil {
+			t.Errorf("Expected an error for %q but got nil", hex)
+		}
+	}
+}
+
+func TestQuoteCard(t *testing.T) {
+	// Arrange
+	quote := db.Quote{Quote: strings.Repeat("All work and no play. ", 10), Page: 69,
+		Book: db.Book{Title: "Book1", Author: db.Author{Name: "Author1"}}}
+	background := color.RGBA{0x10, 0x20, 0x30, 0xff}
+	foreground := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	options := CardOptions{Width: 600, Background: background, Foreground: foreground}
+	// Act
+	card := QuoteCard(quote, options)
+	// Assert
+	bounds := card.Bounds()
+	if bounds.Dx() != 600 {
+		t.Errorf(contentError, 600, bounds.Dx())
+	}
+	// the quote is wrapped to 28 characters per line, which are followed by
+	// an empty line and the title and author in smaller lines
+	lines := len(wrap(printable("\""+quote.Quote+"\""), 28))
+	expectedHeight := 2*cardPadding + lines*30 + 3*20
+	if bounds.Dy() != expectedHeight {
+		t.Errorf(contentError, expectedHeight, bounds.Dy())
+	}
+	if actual := card.RGBAAt(0, 0); actual != background {
+		t.Errorf(contentError, background, actual)
+	}
+	// the top left pixel of the opening quotation mark
+	if actual := card.RGBAAt(cardPadding+quoteScale, cardPadding); actual != foreground {
+		t.Errorf(contentError, foreground, actual)
+	}
+}
+
+func TestQuoteCardDefaults(t *testing.T) {
+	// Arrange
+	quote := db.Quote{Quote: "Quote1", Book: db.Book{Title: "Book1", Author: db.Author{Name: "Author1"}}}
+	// Act
+	card := QuoteCard(quote, CardOptions{})
+	// Assert
+	if width := card.Bounds().Dx(); width != defaultCardWidth {
+		t.Errorf(contentError, defaultCardWidth, width)
+	}
+	expected, err := parseColor(defaultCardBackground)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := card.At(0, 0); actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
+
+func TestCard(t *testing.T) {
+	// Arrange
+	config := Config{CardWidth: 400, CardBackground: "#000"}
+	quote := db.Quote{Quote: "Quote1", Book: db.Book{Title: "Book1", Author: db.Author{Name: "Author1"}}}
+	// Act
+	content, err := config.Card(quote)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	card, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width := card.Bounds().Dx(); width != 400 {
+		t.Errorf(contentError, 400, width)
+	}
+	if r, g, b, _ := card.At(0, 0).RGBA(); r != 0 || g != 0 || b != 0 {
+		t.Errorf(contentError, "black", card.At(0, 0))
+	}
+	config.CardForeground = "blue"
+	if _, err = config.Card(quote); err == nil {
+		t.Error("Expected an error for an invalid color but got nil")
+	}
+}
+
+func TestCardAttachments(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config, err := initConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Cards = true
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	actualMessage, _, err := config.message(quotes, nil)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(actualMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedType := "multipart/mixed"; mediaType != expectedType {
+		t.Fatalf(headerError, expectedType, mediaType)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	// the first part is the body of the mail
+	part, err := reader.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := &mail.Message{Header: mail.Header(part.Header), Body: part}
+	if parts := messageParts(t, body); len(parts) != 2 {
+		t.Errorf(lenError, 2, len(parts))
+	}
+	var names []string
+	for {
+		part, err = reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, part.FileName())
+		content, err := ioutil.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = png.Decode(bytes.NewReader(content)); err != nil {
+			t.Error(err)
+		}
+	}
+	expected := []string{"quote-1.png", "quote-2.png"}
+	if len(names) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(names))
+	}
+	for i, name := range names {
+		if name != expected[i] {
+			t.Errorf(contentError, expected[i], name)
+		}
+	}
+}
+
+func TestQuizCards(t *testing.T) {
+	// Arrange
+	config := Config{CardWidth: 400}
+	quote := db.Quote{Id: 1, Quote: "A quote with a word", Book: db.Book{Title: "Book1", Author: db.Author{Name: "Author1"}}}
+	clozes := map[int]string{quote.Id: "A ____ with a word"}
+	blanked := quote
+	blanked.Quote = clozes[quote.Id]
+	expected, err := config.Card(blanked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	cards, err := config.cards([]db.Quote{quote}, clozes)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 {
+		t.Fatalf(lenError, 1, len(cards))
+	}
+	if !bytes.Equal(cards[0].content, expected) {
+		t.Errorf(contentError, "card of the quiz version", "card of the quote")
+	}
+}
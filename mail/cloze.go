@@ -0,0 +1,118 @@
+package quote
+
+import (
+	db "quote/db"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Settings of the key words which are blanked out of a quote in quiz mode
+const (
+	// minClozeLength is the minimum number of letters of a key word
+	minClozeLength = 4
+	// wordsPerCloze is the number of words of a quote per key word, a quote
+	// has at least one key word
+	wordsPerCloze = 8
+)
+
+// word of a text at the byte offsets start up to end
+type word struct {
+	start, end int
+}
+
+// words returns the words of `text`, which are sequences of letters
+func words(text string) (result []word) {
+	start := -1
+	for i, r := range text {
+		letter := unicode.IsLetter(r)
+		if letter && start < 0 {
+			start = i
+		} else if !letter && start >= 0 {
+			result = append(result, word{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word{start, len(text)})
+	}
+	return
+}
+
+// wordFrequencies returns how often each word (in lower case) occurs in
+// `quotes`
+func wordFrequencies(quotes []db.Quote) map[string]int {
+	frequencies := make(map[string]int)
+	for _, quote := range quotes {
+		for _, w := range words(quote.Quote) {
+			frequencies[strings.ToLower(quote.Quote[w.start:w.end])]++
+		}
+	}
+	return frequencies
+}
+
+// cloze blanks out the key words of `text`, which are its least frequent
+// words according to `frequencies` and among those its longest ones. Each
+// letter of a key word is replaced by an underscore, so that the length of
+// the word is kept as a hint.
+func cloze(text string, frequencies map[string]int) string {
+	all := words(text)
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, w := range all {
+		key := strings.ToLower(text[w.start:w.end])
+		if utf8.RuneCountInString(key) >= minClozeLength && !seen[key] {
+			seen[key] = true
+			candidates = append(candidates, key)
+		}
+	}
+	// the order of the candidates in the text decides between equally
+	// suitable ones
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if frequencies[a] != frequencies[b] {
+			return frequencies[a] < frequencies[b]
+		}
+		return utf8.RuneCountInString(a) > utf8.RuneCountInString(b)
+	})
+	count := (len(all) + wordsPerCloze - 1) / wordsPerCloze
+	if count < len(candidates) {
+		candidates = candidates[:count]
+	}
+	blank := make(map[string]bool)
+	for _, key := range candidates {
+		blank[key] = true
+	}
+	var result strings.Builder
+	last := 0
+	for _, w := range all {
+		if !blank[strings.ToLower(text[w.start:w.end])] {
+			continue
+		}
+		result.WriteString(text[last:w.start])
+		result.WriteString(strings.Repeat("_", utf8.RuneCountInString(text[w.start:w.end])))
+		last = w.end
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// clozes returns the quiz version of each quote of `quotes` by its id, with
+// the word frequencies computed over all quotes of `database`. It is nil if
+// quiz mode is disabled.
+func (c Config) clozes(database *db.Database, quotes []db.Quote) (clozes map[int]string, err error) {
+	if !c.Quiz || len(quotes) == 0 {
+		return
+	}
+	corpus, err := database.GetQuotes()
+	if err != nil {
+		return
+	}
+	frequencies := wordFrequencies(corpus)
+	clozes = make(map[int]string)
+	for _, quote := range quotes {
+		clozes[quote.Id] = cloze(quote.Quote, frequencies)
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package quote
+
+import (
+	"net/mail"
+	db "quote/db"
+	"strings"
+	"testing"
+)
+
+func TestWordFrequencies(t *testing.T) {
+	// Arrange
+	quotes := []db.Quote{
+		{Quote: "The sun is up, the day is long."},
+		{Quote: "Über den Wolken."},
+	}
+	expected := map[string]int{"the": 2, "sun": 1, "is": 2, "up": 1, "day": 1,
+		"long": 1, "über": 1, "den": 1, "wolken": 1}
+	// Act
+	actual := wordFrequencies(quotes)
+	// Assert
+	if len(actual) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(actual))
+	}
+	for word, count := range expected {
+		if actual[word] != count {
+			t.Errorf(contentError, count, actual[word])
+		}
+	}
+}
+
+func TestCloze(t *testing.T) {
+	// Arrange
+	frequencies := map[string]int{"memory": 1, "reading": 5, "strengthens": 3,
+		"regular": 5, "the": 20, "and": 20, "mind": 2, "über": 1, "alles": 4}
+	cases := []struct {
+		text, expected string
+	}{
+		// the least frequent word is blanked out
+		{"Regular reading strengthens the memory.", "Regular reading strengthens the ______."},
+		// the longest word decides between equally frequent ones
+		{"Reading and regular reading.", "_______ and regular _______."},
+		// one key word per eight words
+		{"Memory and the mind, the mind and memory, and the reading mind.",
+			"______ and the ____, the ____ and ______, and the reading ____."},
+		{"Über alles", "____ alles"},
+		// words which are too short are kept
+		{"The end.", "The end."},
+	}
+	for _, c := range cases {
+		// Act
+		actual := cloze(c.text, frequencies)
+		// Assert
+		if actual != c.expected {
+			t.Errorf(contentError, c.expected, actual)
+		}
+	}
+}
+
+func TestQuizMessage(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := database.NewQuote(book)
+	quote.Quote = "Knowledge is power"
+	quote.Page = 1
+	if quote.Id, err = quote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	config := feedbackConfig()
+	config.Quiz = true
+	clozes, err := config.clozes(database, []db.Quote{quote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	actualMessage, _, err := config.message([]db.Quote{quote}, clozes)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(actualMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := messageParts(t, message)
+	if len(parts) != 2 {
+		t.Fatalf(lenError, 2, len(parts))
+	}
+	answer := "https://quotes.example.com/api/quotes/3"
+	for _, part := range parts {
+		body := strings.ReplaceAll(part.body, "\r\n", "\n")
+		quiz := strings.Index(body, "_________ is power")
+		full := strings.Index(body, "Knowledge is power")
+		if quiz < 0 || full < quiz {
+			t.Errorf("%s part does not show the quiz before the full quote:\n%s", part.contentType, body)
+		}
+		if !strings.Contains(body, answer) {
+			t.Errorf("%s part does not contain %q:\n%s", part.contentType, answer, body)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package quote
+
+import (
+	"log"
+	"net/mail"
+	db "quote/db"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDigestSubject = "Weekly quote digest"
+	// favoritesOfDigest is the number of most favorited quotes of a digest
+	favoritesOfDigest = 5
+)
+
+// digestBook are the quotes of a book added in the week of a digest
+type digestBook struct {
+	Book   db.Book
+	Quotes []db.Quote
+}
+
+// digest is passed to the templates of a digest mail
+type digest struct {
+	Subject string
+	Date    time.Time
+	// From and To is the week of the digest
+	From      time.Time
+	To        time.Time
+	Books     []digestBook
+	Topics    []db.Tally
+	Authors   []db.Tally
+	Favorites []db.Tally
+	Size      db.Size
+}
+
+// newDigest collects the statistics of the week before `date`
+func newDigest(database *db.Database, subject string, date time.Time) (data digest, err error) {
+	data = digest{Subject: subject, Date: date, From: date.AddDate(0, 0, -7), To: date}
+	// the record date of a quote has no time, so the digest covers the
+	// seven days before the day of `date`
+	quotes, err := database.RecordedQuotes(data.From, data.To)
+	if err != nil {
+		return
+	}
+	for _, quote := range quotes {
+		last := len(data.Books) - 1
+		if last < 0 || data.Books[last].Book.Id != quote.Book.Id {
+			data.Books = append(data.Books, digestBook{Book: quote.Book})
+			last += 1
+		}
+		data.Books[last].Quotes = append(data.Books[last].Quotes, quote)
+	}
+	if data.Topics, err = database.QuotesPerTopic(); err != nil {
+		return
+	}
+	if data.Authors, err = database.QuotesPerAuthor(); err != nil {
+		return
+	}
+	if data.Favorites, err = database.FavoriteQuotes(favoritesOfDigest); err != nil {
+		return
+	}
+	data.Size, err = database.CollectionSize()
+	return
+}
+
+// digestMessage renders the digest of the week before `date` for the
+// receivers of the configuration, returning the mail and its plain text
+func (c Config) digestMessage(database *db.Database, date time.Time) (message, text string, err error) {
+	textTmpl, err := textTemplate(c.DigestTextTemplate, "digest.txt")
+	if err != nil {
+		return
+	}
+	htmlTmpl, err := htmlTemplate(c.DigestHtmlTemplate, "digest.html")
+	if err != nil {
+		return
+	}
+	sender, err := mail.ParseAddress(c.Sender)
+	if err != nil {
+		return
+	}
+	var receivers []*mail.Address
+	for _, address := range c.Receiver {
+		var receiver *mail.Address
+		receiver, err = mail.ParseAddress(address)
+		if err != nil {
+			return
+		}
+		receivers = append(receivers, receiver)
+	}
+	subject := c.DigestSubject
+	if subject == "" {
+		subject = defaultDigestSubject
+	}
+	data, err := newDigest(database, subject, date)
+	if err != nil {
+		return
+	}
+	textBody, htmlBody, err := render(textTmpl, htmlTmpl, data)
+	if err != nil {
+		return
+	}
+	var buffer strings.Builder
+	err = header(&buffer, sender, receivers, subject, date)
+	if err != nil {
+		return
+	}
+	err = alternative(&buffer, textBody, htmlBody)
+	message, text = buffer.String(), string(textBody)
+	return
+}
+
+// digestRecipients returns the distinct recipients of all subscriptions
+func digestRecipients(database *db.Database) (recipients []string, err error) {
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, subscription := range subscriptions {
+		if !seen[subscription.Recipient] {
+			seen[subscription.Recipient] = true
+			recipients = append(recipients, subscription.Recipient)
+		}
+	}
+	return
+}
+
+// enqueueDigest stores the digest of the week before `date` in the outbox for
+// every subscribed recipient
+func (c Config) enqueueDigest(database *db.Database, date time.Time) (ids []int, err error) {
+	recipients, err := digestRecipients(database)
+	if err != nil {
+		return
+	}
+	subject := c.DigestSubject
+	if subject == "" {
+		subject = defaultDigestSubject
+	}
+	// the digests are rendered before they are queued, so that the
+	// transaction only stores them
+	bodies, texts := make([]string, len(recipients)), make([]string, len(recipients))
+	for i, recipient := range recipients {
+		settings := c
+		settings.Receiver = []string{recipient}
+		if bodies[i], texts[i], err = settings.digestMessage(database, date); err != nil {
+			return
+		}
+	}
+	err = database.WithTx(func(tx *db.Tx) error {
+		for i, recipient := range recipients {
+			message := tx.NewMessage(recipient, subject, bodies[i], nil)
+			message.Text = texts[i]
+			id, err := message.Commit()
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		ids = nil
+	}
+	return
+}
+
+// runDigest sends the digest according to DigestSchedule until `stop` is
+// closed
+func (c Config) runDigest(database *db.Database, clock Clock, stop <-chan struct{}) error {
+	schedule, err := ParseSchedule(c.DigestSchedule)
+	if err != nil {
+		return err
+	}
+	Scheduler{Schedule: schedule, Clock: clock}.Run(stop, func(activation time.Time) {
+		if _, err := c.enqueueDigest(database, activation); err != nil {
+			log.Println(err)
+			return
+		}
+		c.deliverOutbox(database, clock)
+	})
+	return nil
+}
@@ -0,0 +1,162 @@
+package quote
+
+import (
+	"net/mail"
+	db "quote/db"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDigestMessage(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err = database.NewFeedback(2, "to@mail.com", db.FeedbackFavorite).Commit(); err != nil {
+		t.Fatal(err)
+	}
+	config := Config{Sender: "from@mail.com", Receiver: []string{"to@mail.com"}}
+	// both quotes of the test database have been recorded on 1999-01-01
+	date := time.Date(1999, 1, 4, 7, 30, 0, 0, time.UTC)
+	// Act
+	message, _, err := config.digestMessage(database, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	parsed, err := mail.ReadMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject := parsed.Header.Get("Subject"); subject != defaultDigestSubject {
+		t.Errorf(headerError, defaultDigestSubject, subject)
+	}
+	parts := messageParts(t, parsed)
+	if expectedLen := 2; len(parts) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(parts))
+	}
+	text := strings.ReplaceAll(parts[0].body, "\r\n", "\n")
+	for _, expected := range []string{
+		"Book1 by Author1\n  'Quote1' (page 69)",
+		"Book2 by Author2\n  'Quote2' (page 69)",
+		"Topic1: 1",
+		"Author2: 1",
+		"Most favorited quotes:\n  'Quote2' (1)",
+		"2 quotes of 2 books by 2 authors",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf(contentError, expected, text)
+		}
+	}
+}
+
+func TestDigestWithoutNewQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// Act
+	data, err := newDigest(database, defaultDigestSubject, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC))
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Books) != 0 {
+		t.Errorf(lenError, 0, len(data.Books))
+	}
+	if expected := (db.Size{Quotes: 2, Books: 2, Authors: 2, Topics: 2, Languages: 2}); data.Size != expected {
+		t.Errorf(contentError, expected, data.Size)
+	}
+}
+
+func TestEnqueueDigest(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, recipient := range []string{"first@mail.com", "second@mail.com", "first@mail.com"} {
+		if _, err = database.NewSubscription(recipient).Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := Config{Sender: "from@mail.com", DigestSubject: "Digest"}
+	// Act
+	ids, err := config.enqueueDigest(database, time.Now())
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 2; len(ids) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(ids))
+	}
+	for i, recipient := range []string{"first@mail.com", "second@mail.com"} {
+		message, err := database.GetMessage(ids[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if message.Recipient != recipient || message.Subject != "Digest" {
+			t.Errorf(contentError, recipient, message)
+		}
+		if message.QuoteIds != "" {
+			t.Errorf(contentError, "", message.QuoteIds)
+		}
+	}
+}
+
+func TestDigestNotification(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err = database.NewSubscription("to@mail.com").Commit(); err != nil {
+		t.Fatal(err)
+	}
+	var output strings.Builder
+	config := Config{Sender: "from@mail.com"}
+	ids, err := config.enqueueDigest(database, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := database.GetMessage(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	notification, err := config.notification(database, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = StdoutNotifier{Writer: &output}.Notify(notification)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "2 quotes of 2 books by 2 authors"
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf(contentError, expected, output.String())
+	}
+}
+
+func TestRunDigestInvalidSchedule(t *testing.T) {
+	// Arrange
+	config := Config{DigestSchedule: "fortnightly"}
+	// Act
+	err := config.runDigest(nil, &fakeClock{}, nil)
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
+}
@@ -0,0 +1,189 @@
+package quote
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Signing algorithms of DKIM signatures
+const (
+	DkimRsaSha256     = "rsa-sha256"
+	DkimEd25519Sha256 = "ed25519-sha256"
+)
+
+// dkimHeaders are the header fields signed if present, which are the ones
+// added by this application
+var dkimHeaders = []string{"From", "To", "Subject", "Date", "Message-ID",
+	"MIME-Version", "Content-Type", "Content-Transfer-Encoding",
+	"List-Unsubscribe", "List-Unsubscribe-Post"}
+
+// headerField is a header field of a mail as it appears in the mail,
+// including folding whitespace but without the terminating CRLF
+type headerField struct {
+	name string
+	raw  string
+}
+
+// splitMessage returns the header fields and the body of `message`
+func splitMessage(message []byte) (fields []headerField, body []byte, err error) {
+	end := bytes.Index(message, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil, nil, errors.New("message has no body")
+	}
+	body = message[end+4:]
+	for _, line := range strings.Split(string(message[:end]), "\r\n") {
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			if len(fields) == 0 {
+				return nil, nil, errors.New("message starts with a folded line")
+			}
+			fields[len(fields)-1].raw += "\r\n" + line
+			continue
+		}
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			return nil, nil, fmt.Errorf("invalid header field %q", line)
+		}
+		fields = append(fields, headerField{name: strings.TrimSpace(line[:colon]), raw: line})
+	}
+	return
+}
+
+// compressSpace replaces each sequence of whitespace of `s` with a single
+// space
+func compressSpace(s string) string {
+	var result strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
+		}
+		if space {
+			result.WriteByte(' ')
+			space = false
+		}
+		result.WriteRune(r)
+	}
+	if space {
+		result.WriteByte(' ')
+	}
+	return result.String()
+}
+
+// relaxedHeader canonicalizes the header field `raw` with the relaxed
+// algorithm (RFC 6376, section 3.4.2) including the terminating CRLF
+func relaxedHeader(raw string) string {
+	colon := strings.Index(raw, ":")
+	name := strings.ToLower(strings.TrimSpace(raw[:colon]))
+	value := strings.NewReplacer("\r\n", "").Replace(raw[colon+1:])
+	value = strings.TrimSpace(compressSpace(value))
+	return name + ":" + value + "\r\n"
+}
+
+// relaxedBody canonicalizes `body` with the relaxed algorithm (RFC 6376,
+// section 3.4.4)
+func relaxedBody(body []byte) []byte {
+	lines := strings.Split(string(body), "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(compressSpace(line), " ")
+	}
+	// empty lines at the end of the body are ignored
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// loadDkimKey reads the PEM encoded private key at `path`, which is either
+// an RSA key (PKCS #1 or PKCS #8) or an Ed25519 key (PKCS #8), and returns it
+// with its signing algorithm
+func loadDkimKey(path string) (signer crypto.Signer, algorithm string, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, "", fmt.Errorf("no PEM encoded key in %s", path)
+	}
+	var key interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, DkimRsaSha256, nil
+	case ed25519.PrivateKey:
+		return key, DkimEd25519Sha256, nil
+	}
+	return nil, "", fmt.Errorf("unsupported DKIM key type %T", key)
+}
+
+// dkimSign adds a DKIM-Signature header field to `message` if DkimSelector,
+// DkimDomain and DkimKeyFile are configured. The signature uses the relaxed
+// canonicalization for the header and the body.
+func (c Config) dkimSign(message []byte) ([]byte, error) {
+	if c.DkimKeyFile == "" || c.DkimDomain == "" || c.DkimSelector == "" {
+		return message, nil
+	}
+	signer, algorithm, err := loadDkimKey(c.DkimKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	fields, body, err := splitMessage(message)
+	if err != nil {
+		return nil, err
+	}
+	bodyHash := sha256.Sum256(relaxedBody(body))
+	// the fields are signed from the bottom up, as recommended for fields
+	// occurring several times (RFC 6376, section 5.4.2)
+	var names []string
+	var signed strings.Builder
+	for _, name := range dkimHeaders {
+		for i := len(fields) - 1; i >= 0; i -= 1 {
+			if strings.EqualFold(fields[i].name, name) {
+				names = append(names, strings.ToLower(name))
+				signed.WriteString(relaxedHeader(fields[i].raw))
+			}
+		}
+	}
+	value := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s; t=%d; h=%s; bh=%s; b=",
+		algorithm, c.DkimDomain, c.DkimSelector, time.Now().Unix(),
+		strings.Join(names, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+	// the signature field itself is signed without its signature and the
+	// terminating CRLF
+	signed.WriteString(strings.TrimSuffix(relaxedHeader("DKIM-Signature: "+value), "\r\n"))
+	digest := sha256.Sum256([]byte(signed.String()))
+	var signature []byte
+	if algorithm == DkimRsaSha256 {
+		signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	} else {
+		// Ed25519 signs the SHA-256 hash itself (RFC 8463)
+		signature, err = signer.Sign(rand.Reader, digest[:], crypto.Hash(0))
+	}
+	if err != nil {
+		return nil, err
+	}
+	header := "DKIM-Signature: " + value + base64.StdEncoding.EncodeToString(signature) + "\r\n"
+	return append([]byte(header), message...), nil
+}
@@ -0,0 +1,286 @@
+package quote
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// signatureValue matches the value of the b= tag of a DKIM-Signature
+var signatureValue = regexp.MustCompile(`(;\s*b=)[^;]*`)
+
+// verifyDkim verifies the first DKIM-Signature of `message` with
+// `publicKey` like a receiving mail server, which would look up the key in
+// the DNS
+func verifyDkim(message []byte, publicKey crypto.PublicKey) error {
+	fields, body, err := splitMessage(message)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 || !strings.EqualFold(fields[0].name, "DKIM-Signature") {
+		return errors.New("message has no DKIM-Signature")
+	}
+	signature := fields[0]
+	tags := make(map[string]string)
+	value := signature.raw[strings.Index(signature.raw, ":")+1:]
+	for _, tag := range strings.Split(value, ";") {
+		parts := strings.SplitN(strings.TrimSpace(tag), "=", 2)
+		if len(parts) == 2 {
+			tags[parts[0]] = strings.Join(strings.Fields(parts[1]), "")
+		}
+	}
+	if tags["v"] != "1" || tags["c"] != "relaxed/relaxed" {
+		return fmt.Errorf("unsupported signature %v", tags)
+	}
+	bodyHash := sha256.Sum256(relaxedBody(body))
+	if tags["bh"] != base64.StdEncoding.EncodeToString(bodyHash[:]) {
+		return errors.New("body hash does not match")
+	}
+	// each name in h= refers to the next field of that name from the bottom
+	var signed strings.Builder
+	used := make(map[string]int)
+	for _, name := range strings.Split(tags["h"], ":") {
+		seen := 0
+		for i := len(fields) - 1; i > 0; i -= 1 {
+			if strings.EqualFold(fields[i].name, name) {
+				if seen == used[name] {
+					signed.WriteString(relaxedHeader(fields[i].raw))
+					break
+				}
+				seen += 1
+			}
+		}
+		used[name] += 1
+	}
+	unsigned := signatureValue.ReplaceAllString(signature.raw, "${1}")
+	signed.WriteString(strings.TrimSuffix(relaxedHeader(unsigned), "\r\n"))
+	digest := sha256.Sum256([]byte(signed.String()))
+	sig, err := base64.StdEncoding.DecodeString(tags["b"])
+	if err != nil {
+		return err
+	}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if tags["a"] != DkimRsaSha256 {
+			return fmt.Errorf("unexpected algorithm %s", tags["a"])
+		}
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig)
+	case ed25519.PublicKey:
+		if tags["a"] != DkimEd25519Sha256 {
+			return fmt.Errorf("unexpected algorithm %s", tags["a"])
+		}
+		if !ed25519.Verify(key, digest[:], sig) {
+			return errors.New("signature does not match")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported key %T", publicKey)
+}
+
+// writeKey stores `key` PEM encoded in a temporary file and returns its path
+func writeKey(t *testing.T, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), "dkim.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// dkimKeys returns the paths of generated RSA (PKCS #1 and #8) and Ed25519
+// private keys with their public keys
+func dkimKeys(t *testing.T) map[string]crypto.PublicKey {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8Rsa, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8Ed25519, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.PublicKey{
+		writeKey(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)): &rsaKey.PublicKey,
+		writeKey(t, "PRIVATE KEY", pkcs8Rsa):                                &rsaKey.PublicKey,
+		writeKey(t, "PRIVATE KEY", pkcs8Ed25519):                            publicKey,
+	}
+}
+
+func TestRelaxedCanonicalization(t *testing.T) {
+	// Arrange
+	// example of RFC 6376, section 3.4.5
+	headers := map[string]string{
+		"A: X":             "a:X\r\n",
+		"B : Y\t\r\n\tZ  ": "b:Y Z\r\n",
+	}
+	body := []byte(" C \r\nD \t E\r\n\r\n\r\n")
+	for raw, expected := range headers {
+		// Act
+		actual := relaxedHeader(raw)
+		// Assert
+		if actual != expected {
+			t.Errorf(contentError, expected, actual)
+		}
+	}
+	// Act
+	actual := string(relaxedBody(body))
+	// Assert
+	if expected := " C\r\nD E\r\n"; actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+	if empty := relaxedBody([]byte("\r\n\r\n")); len(empty) != 0 {
+		t.Errorf(contentError, "", string(empty))
+	}
+}
+
+func TestDkimSign(t *testing.T) {
+	for path, publicKey := range dkimKeys(t) {
+		// Arrange
+		config := feedbackConfig()
+		config.DkimSelector = "reminder"
+		config.DkimDomain = "mail.com"
+		config.DkimKeyFile = path
+		message, _, err := config.message(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Act
+		signed, err := config.dkimSign([]byte(message))
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = verifyDkim(signed, publicKey); err != nil {
+			t.Errorf("Signature of %T does not verify: %v", publicKey, err)
+		}
+		// changing a signed header field or the body breaks the signature
+		tampered := strings.Replace(string(signed), "Subject: Quote-reminder", "Subject: Quote-remainder", 1)
+		if err = verifyDkim([]byte(tampered), publicKey); err == nil {
+			t.Errorf("Expected the signature of %T to fail for a changed subject", publicKey)
+		}
+		tampered = strings.Replace(string(signed), ">Unsubscribe<", ">Unsubscribe now<", 1)
+		if err = verifyDkim([]byte(tampered), publicKey); err == nil {
+			t.Errorf("Expected the signature of %T to fail for a changed body", publicKey)
+		}
+		// folding and whitespace are ignored by the relaxed canonicalization
+		refolded := strings.Replace(string(signed), "Subject: Quote-reminder", "Subject:  Quote-reminder \r\n\t", 1)
+		if err = verifyDkim([]byte(refolded), publicKey); err != nil {
+			t.Errorf("Signature of %T does not verify after refolding: %v", publicKey, err)
+		}
+	}
+}
+
+func TestDkimSignCanonicalForm(t *testing.T) {
+	// the canonical forms are written out by hand (RFC 6376, section 3.4),
+	// so that the signature is not only checked against the canonicalization
+	// it was created with
+	message := "From: from@mail.com\r\n" +
+		"To: to@mail.com\r\n" +
+		"Subject:  Quote \t reminder \r\n\tfolded\r\n" +
+		"X-Mailer: not signed\r\n" +
+		"Date: Fri, 4 Mar 2022 05:06:07 +0000\r\n" +
+		"\r\n" +
+		" Hello  \t world \r\n" +
+		"\r\n" +
+		"Bye\r\n" +
+		"\r\n" +
+		"\r\n"
+	headers := "from:from@mail.com\r\n" +
+		"to:to@mail.com\r\n" +
+		"subject:Quote reminder folded\r\n" +
+		"date:Fri, 4 Mar 2022 05:06:07 +0000\r\n"
+	// base64 of the SHA-256 of " Hello world\r\n\r\nBye\r\n"
+	bodyHash := "DUcL/v1A5e/VSTpA7HyDTFyVo495hYORnCFuuQnapmE="
+	for path, publicKey := range dkimKeys(t) {
+		// Arrange
+		config := Config{DkimSelector: "reminder", DkimDomain: "mail.com", DkimKeyFile: path}
+		// Act
+		signed, err := config.dkimSign([]byte(message))
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		end := strings.Index(string(signed), "\r\n")
+		prefix := "DKIM-Signature: "
+		if !strings.HasPrefix(string(signed), prefix) || string(signed[end+2:]) != message {
+			t.Fatalf(contentError, "a DKIM-Signature before the message", string(signed))
+		}
+		value := string(signed[len(prefix):end])
+		tags := make(map[string]string)
+		for _, tag := range strings.Split(value, "; ") {
+			parts := strings.SplitN(tag, "=", 2)
+			tags[parts[0]] = parts[1]
+		}
+		if tags["bh"] != bodyHash {
+			t.Errorf(contentError, bodyHash, tags["bh"])
+		}
+		if expected := "from:to:subject:date"; tags["h"] != expected {
+			t.Errorf(contentError, expected, tags["h"])
+		}
+		// the signature field is single spaced and ends with b=, so its
+		// canonical form only lowercases the name
+		data := headers + "dkim-signature:" + strings.TrimSuffix(value, tags["b"])
+		digest := sha256.Sum256([]byte(data))
+		signature, err := base64.StdEncoding.DecodeString(tags["b"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch key := publicKey.(type) {
+		case *rsa.PublicKey:
+			err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+		case ed25519.PublicKey:
+			if !ed25519.Verify(key, digest[:], signature) {
+				err = errors.New("signature does not match")
+			}
+		}
+		if err != nil {
+			t.Errorf("Signature of %T does not verify against the canonical form: %v", publicKey, err)
+		}
+	}
+}
+
+func TestDkimSignDisabled(t *testing.T) {
+	// Arrange
+	config := Config{DkimDomain: "mail.com", DkimKeyFile: "missing.pem"}
+	message := []byte("Subject: test\r\n\r\nbody\r\n")
+	// Act
+	signed, err := config.dkimSign(message)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(signed) != string(message) {
+		t.Errorf(contentError, string(message), string(signed))
+	}
+}
+
+func TestDkimSignInvalidKey(t *testing.T) {
+	// Arrange
+	config := Config{DkimSelector: "reminder", DkimDomain: "mail.com",
+		DkimKeyFile: writeKey(t, "PRIVATE KEY", []byte("invalid"))}
+	// Act
+	_, err := config.dkimSign([]byte("Subject: test\r\n\r\nbody\r\n"))
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for an invalid key but got nil")
+	}
+}
@@ -0,0 +1,289 @@
+package quote
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMail is a mail received by the fakeSmtp server
+type fakeMail struct {
+	From string
+	To   []string
+	Data string
+	// TLS is set if the mail was transmitted encrypted
+	TLS bool
+	// Auth is the mechanism the client authenticated with
+	Auth string
+}
+
+// fakeSmtpOptions configure the behaviour of a fakeSmtp server
+type fakeSmtpOptions struct {
+	// Reject all recipients with a permanent error
+	Reject bool
+	// TLS enables STARTTLS or, if Implicit is set, implicit TLS
+	TLS      *tls.Config
+	Implicit bool
+	// Mechanisms are the advertised authentication mechanisms, no AUTH
+	// extension is advertised if empty
+	Mechanisms []string
+	// Username and Password which are accepted, any credentials are
+	// accepted if Password is empty
+	Username string
+	Password string
+}
+
+// fakeSmtp is a minimal in-process SMTP server which accepts every mail,
+// unless configured otherwise.
+type fakeSmtp struct {
+	fakeSmtpOptions
+	listener net.Listener
+
+	lock  sync.Mutex
+	mails []fakeMail
+}
+
+// newFakeSmtp starts a fakeSmtp server on a random local port, which is
+// stopped at the end of the test
+func newFakeSmtp(t *testing.T, options fakeSmtpOptions) *fakeSmtp {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Implicit {
+		listener = tls.NewListener(listener, options.TLS)
+	}
+	server := &fakeSmtp{fakeSmtpOptions: options, listener: listener}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+// testCertificate creates a self-signed certificate for 127.0.0.1 and returns
+// the server configuration using it together with the path of a file
+// containing the certificate in PEM encoding
+func testCertificate(t *testing.T) (*tls.Config, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return &tls.Config{Certificates: []tls.Certificate{certificate}}, path
+}
+
+// Port returns the port the server is listening on
+func (s *fakeSmtp) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Mails returns the mails received so far
+func (s *fakeSmtp) Mails() []fakeMail {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]fakeMail(nil), s.mails...)
+}
+
+func (s *fakeSmtp) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSmtp) handle(conn net.Conn) {
+	defer func() { conn.Close() }()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+	readLine := func() (string, error) {
+		line, err := reader.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	_, encrypted := conn.(*tls.Conn)
+	auth := ""
+	reply("220 localhost fake SMTP")
+	var current fakeMail
+	for {
+		line, err := readLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			extensions := []string{"localhost"}
+			if s.TLS != nil && !encrypted {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if len(s.Mechanisms) > 0 {
+				extensions = append(extensions, "AUTH "+strings.Join(s.Mechanisms, " "))
+			}
+			for i, extension := range extensions {
+				if i == len(extensions)-1 {
+					reply("250 " + extension)
+				} else {
+					reply("250-" + extension)
+				}
+			}
+		case strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case command == "STARTTLS" && s.TLS != nil && !encrypted:
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.TLS)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+			encrypted = true
+		case strings.HasPrefix(command, "AUTH "):
+			fields := strings.Fields(line)
+			mechanism := strings.ToUpper(fields[1])
+			username, password, ok := s.authenticate(mechanism, fields[2:], reply, readLine)
+			if ok && (s.Password == "" || username == s.Username && password == s.Password) {
+				auth = mechanism
+				reply("235 authenticated")
+			} else {
+				reply("535 authentication failed")
+			}
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			current = fakeMail{
+				From: strings.Trim(line[len("MAIL FROM:"):], "<> "),
+				TLS:  encrypted,
+				Auth: auth,
+			}
+			reply("250 ok")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			if s.Reject {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			current.To = append(current.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 ok")
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			current.Data = data.String()
+			s.lock.Lock()
+			s.mails = append(s.mails, current)
+			s.lock.Unlock()
+			reply("250 ok")
+		case command == "RSET" || command == "NOOP":
+			reply("250 ok")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// authenticate runs the exchange of an AUTH command and returns the
+// credentials sent by the client. For CRAM-MD5 the password is only returned
+// if the digest matches the configured Password.
+func (s *fakeSmtp) authenticate(mechanism string, initial []string,
+	reply func(string), readLine func() (string, error)) (username, password string, ok bool) {
+	challenge := func(text string) (string, bool) {
+		reply("334 " + base64.StdEncoding.EncodeToString([]byte(text)))
+		line, err := readLine()
+		if err != nil {
+			return "", false
+		}
+		response, err := base64.StdEncoding.DecodeString(line)
+		return string(response), err == nil
+	}
+	switch mechanism {
+	case "PLAIN":
+		var response []byte
+		if len(initial) > 0 {
+			var err error
+			response, err = base64.StdEncoding.DecodeString(initial[0])
+			if err != nil {
+				return
+			}
+		} else {
+			text, valid := challenge("")
+			if !valid {
+				return
+			}
+			response = []byte(text)
+		}
+		parts := strings.Split(string(response), "\x00")
+		if len(parts) != 3 {
+			return
+		}
+		return parts[1], parts[2], true
+	case "LOGIN":
+		if username, ok = challenge("Username:"); !ok {
+			return
+		}
+		password, ok = challenge("Password:")
+		return
+	case "CRAM-MD5":
+		nonce := "<1896.697170952@localhost>"
+		response, valid := challenge(nonce)
+		fields := strings.Fields(response)
+		if !valid || len(fields) != 2 {
+			return
+		}
+		digest := hmac.New(md5.New, []byte(s.Password))
+		digest.Write([]byte(nonce))
+		if hex.EncodeToString(digest.Sum(nil)) != fields[1] {
+			return fields[0], "", true
+		}
+		return fields[0], s.Password, true
+	}
+	return
+}
@@ -0,0 +1,162 @@
+package quote
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	db "quote/db"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions of the feedback links contained in the reminders
+const (
+	FavoriteAction    = db.FeedbackFavorite
+	LessAction        = db.FeedbackLess
+	SnoozeAction      = db.FeedbackSnooze
+	UnsubscribeAction = "unsubscribe"
+)
+
+// snoozeDuration is the time a snoozed quote is not sent to its recipient
+const snoozeDuration = 30 * 24 * time.Hour
+
+// defaultFeedbackExpiry is the number of days a feedback link is valid
+const defaultFeedbackExpiry = 90
+
+// issuedLayout is the layout of the date a feedback link was issued at
+const issuedLayout = "2006-01-02"
+
+// Errors of Config.Feedback
+var (
+	ErrInvalidToken  = errors.New("invalid feedback token")
+	ErrExpiredToken  = errors.New("expired feedback token")
+	ErrUnknownAction = errors.New("unknown feedback action")
+)
+
+// feedbackToken signs an action of `recipient` on the quote with `quoteId`
+// (0 for unsubscribing), which was `issued` at the date (see issuedLayout),
+// with the FeedbackSecret
+func (c Config) feedbackToken(action, recipient string, quoteId int, issued string) string {
+	mac := hmac.New(sha256.New, []byte(c.FeedbackSecret.Value()))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s", action, recipient, quoteId, issued)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// FeedbackLink returns the signed link for an action of `recipient` on the
+// quote with `quoteId`, which is empty if FeedbackUrl or FeedbackSecret are
+// not configured
+func (c Config) FeedbackLink(action, recipient string, quoteId int) string {
+	if c.FeedbackUrl == "" || c.FeedbackSecret.Value() == "" {
+		return ""
+	}
+	query := url.Values{}
+	query.Set("recipient", recipient)
+	if quoteId != 0 {
+		query.Set("quote", strconv.Itoa(quoteId))
+	}
+	issued := time.Now().UTC().Format(issuedLayout)
+	query.Set("issued", issued)
+	query.Set("token", c.feedbackToken(action, recipient, quoteId, issued))
+	return fmt.Sprintf("%s/api/feedback/%s?%s",
+		strings.TrimRight(c.FeedbackUrl, "/"), action, query.Encode())
+}
+
+// VerifyFeedback verifies the `token` of a feedback link, which was `issued`
+// at the date (see issuedLayout) and is valid for FeedbackExpiry days
+func (c Config) VerifyFeedback(action, recipient string, quoteId int, issued, token string) error {
+	switch action {
+	case FavoriteAction, LessAction, SnoozeAction, UnsubscribeAction:
+	default:
+		return ErrUnknownAction
+	}
+	expected := c.feedbackToken(action, recipient, quoteId, issued)
+	if c.FeedbackSecret.Value() == "" || !hmac.Equal([]byte(token), []byte(expected)) {
+		return ErrInvalidToken
+	}
+	date, err := time.Parse(issuedLayout, issued)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	expiry := c.FeedbackExpiry
+	if expiry <= 0 {
+		expiry = defaultFeedbackExpiry
+	}
+	if time.Now().After(date.AddDate(0, 0, expiry+1)) {
+		return ErrExpiredToken
+	}
+	return nil
+}
+
+// Feedback verifies the `token` of a feedback link (see VerifyFeedback) and
+// applies its action: favorite and less quotes are sent more or less often to
+// the recipient, snoozed quotes are not sent to the recipient for 30 days and
+// unsubscribing deletes all subscriptions of the recipient.
+func (c Config) Feedback(database *db.Database, action, recipient string, quoteId int, issued, token string) (err error) {
+	if err = c.VerifyFeedback(action, recipient, quoteId, issued, token); err != nil {
+		return
+	}
+	if action == UnsubscribeAction {
+		return database.WithTx(func(tx *db.Tx) error {
+			subscriptions, err := tx.GetSubscriptions()
+			if err != nil {
+				return err
+			}
+			for _, subscription := range subscriptions {
+				if subscription.Recipient == recipient {
+					if err = subscription.Delete(); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+	feedback := database.NewFeedback(quoteId, recipient, action)
+	if action == SnoozeAction {
+		feedback.Until = feedback.FeedbackDate.Add(snoozeDuration)
+	}
+	_, err = feedback.Commit()
+	return
+}
+
+// applyFeedback removes the quotes snoozed by any of `receivers` at `date`
+// from `quotes` and adjusts the delivery `counts`: a quote marked as favorite
+// is treated as delivered once less, a quote to be shown less as delivered
+// twice more. This way favorites are repeated earlier and the others later.
+func applyFeedback(database *db.Database, receivers []string, quotes []db.Quote,
+	counts map[int]int, date time.Time) (remaining []db.Quote, err error) {
+	snoozed := make(map[int]bool)
+	for _, receiver := range receivers {
+		var feedback []db.Feedback
+		feedback, err = database.FeedbackOf(receiver)
+		if err != nil {
+			return
+		}
+		for _, entry := range feedback {
+			switch entry.Kind {
+			case db.FeedbackSnooze:
+				if entry.Until.After(date) {
+					snoozed[entry.QuoteId] = true
+				}
+			case db.FeedbackLess:
+				if counts != nil {
+					counts[entry.QuoteId] += 2
+				}
+			case db.FeedbackFavorite:
+				if counts != nil && counts[entry.QuoteId] > 0 {
+					counts[entry.QuoteId] -= 1
+				}
+			}
+		}
+	}
+	for _, quote := range quotes {
+		if !snoozed[quote.Id] {
+			remaining = append(remaining, quote)
+		}
+	}
+	return
+}
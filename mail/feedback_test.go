@@ -0,0 +1,281 @@
+package quote
+
+import (
+	"net/mail"
+	"net/url"
+	db "quote/db"
+	secret "quote/secret"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func feedbackConfig() Config {
+	return Config{
+		Sender:         "from@mail.com",
+		Receiver:       []string{"to@mail.com"},
+		FeedbackUrl:    "https://quotes.example.com/",
+		FeedbackSecret: secret.New("secret"),
+	}
+}
+
+// linkToken returns the recipient, quote id, issue date and token of a
+// feedback link
+func linkToken(t *testing.T, link string) (string, int, string, string) {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	quoteId := 0
+	if val := query.Get("quote"); val != "" {
+		if quoteId, err = strconv.Atoi(val); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return query.Get("recipient"), quoteId, query.Get("issued"), query.Get("token")
+}
+
+func TestFeedbackLink(t *testing.T) {
+	// Arrange
+	config := feedbackConfig()
+	// Act
+	link := config.FeedbackLink(FavoriteAction, "to@mail.com", 2)
+	unconfigured := Config{}.FeedbackLink(FavoriteAction, "to@mail.com", 2)
+	// Assert
+	expectedPrefix := "https://quotes.example.com/api/feedback/favorite?"
+	if !strings.HasPrefix(link, expectedPrefix) {
+		t.Errorf(contentError, expectedPrefix, link)
+	}
+	if recipient, quoteId, _, _ := linkToken(t, link); recipient != "to@mail.com" || quoteId != 2 {
+		t.Errorf(contentError, "to@mail.com and quote 2", link)
+	}
+	if unconfigured != "" {
+		t.Errorf(contentError, "", unconfigured)
+	}
+}
+
+func TestFeedbackInvalidToken(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := feedbackConfig()
+	_, _, issued, token := linkToken(t, config.FeedbackLink(FavoriteAction, "to@mail.com", 2))
+	// Act
+	otherQuote := config.Feedback(database, FavoriteAction, "to@mail.com", 1, issued, token)
+	otherAction := config.Feedback(database, SnoozeAction, "to@mail.com", 2, issued, token)
+	otherRecipient := config.Feedback(database, FavoriteAction, "x@mail.com", 2, issued, token)
+	otherIssued := config.Feedback(database, FavoriteAction, "to@mail.com", 2, "2099-01-01", token)
+	unknown := config.Feedback(database, "like", "to@mail.com", 2, issued, token)
+	// Assert
+	for _, err := range []error{otherQuote, otherAction, otherRecipient, otherIssued} {
+		if err != ErrInvalidToken {
+			t.Errorf(contentError, ErrInvalidToken, err)
+		}
+	}
+	if unknown != ErrUnknownAction {
+		t.Errorf(contentError, ErrUnknownAction, unknown)
+	}
+	feedback, err := database.GetFeedback()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feedback) != 0 {
+		t.Errorf(lenError, 0, len(feedback))
+	}
+}
+
+func TestFeedbackExpiredToken(t *testing.T) {
+	// Arrange
+	config := feedbackConfig()
+	config.FeedbackExpiry = 7
+	recent := time.Now().AddDate(0, 0, -7).UTC().Format(issuedLayout)
+	old := time.Now().AddDate(0, 0, -9).UTC().Format(issuedLayout)
+	// Act
+	recentErr := config.VerifyFeedback(FavoriteAction, "to@mail.com", 2, recent,
+		config.feedbackToken(FavoriteAction, "to@mail.com", 2, recent))
+	oldErr := config.VerifyFeedback(FavoriteAction, "to@mail.com", 2, old,
+		config.feedbackToken(FavoriteAction, "to@mail.com", 2, old))
+	// Assert
+	if recentErr != nil {
+		t.Errorf(contentError, nil, recentErr)
+	}
+	if oldErr != ErrExpiredToken {
+		t.Errorf(contentError, ErrExpiredToken, oldErr)
+	}
+}
+
+func TestFeedbackSnooze(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := feedbackConfig()
+	recipient, quoteId, issued, token := linkToken(t, config.FeedbackLink(SnoozeAction, "to@mail.com", 1))
+	// Act
+	err = config.Feedback(database, SnoozeAction, recipient, quoteId, issued, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotes, err := config.selectQuotes(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(quotes) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(quotes))
+	}
+	if quotes[0].Id == 1 {
+		t.Errorf("Snoozed quote %d was selected", quotes[0].Id)
+	}
+	// other recipients are not affected
+	config.Receiver = []string{"other@mail.com"}
+	if quotes, err = config.selectQuotes(database); len(quotes) != 2 {
+		t.Errorf(lenError, 2, len(quotes))
+	}
+}
+
+func TestApplyFeedbackCounts(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, entry := range []db.Feedback{
+		database.NewFeedback(1, "to@mail.com", db.FeedbackFavorite),
+		database.NewFeedback(2, "to@mail.com", db.FeedbackLess),
+	} {
+		if _, err = entry.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts := map[int]int{1: 2, 2: 1}
+	pool := testPool()
+	// Act
+	remaining, err := applyFeedback(database, []string{"to@mail.com"}, pool, counts, time.Now())
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != len(pool) {
+		t.Errorf(lenError, len(pool), len(remaining))
+	}
+	if counts[1] != 1 || counts[2] != 3 {
+		t.Errorf(contentError, map[int]int{1: 1, 2: 3}, counts)
+	}
+}
+
+func TestFeedbackUnsubscribe(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	for _, recipient := range []string{"to@mail.com", "other@mail.com", "to@mail.com"} {
+		if _, err = database.NewSubscription(recipient).Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := feedbackConfig()
+	recipient, quoteId, issued, token := linkToken(t, config.FeedbackLink(UnsubscribeAction, "to@mail.com", 0))
+	// Act
+	err = config.Feedback(database, UnsubscribeAction, recipient, quoteId, issued, token)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(subscriptions) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(subscriptions))
+	}
+	if subscriptions[0].Recipient != "other@mail.com" {
+		t.Errorf(contentError, "other@mail.com", subscriptions[0].Recipient)
+	}
+}
+
+func TestConfigMessageFeedbackLinks(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := feedbackConfig()
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	message, _, err := config.message(quotes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	parsed, err := mail.ReadMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, parts := parsed.Header, messageParts(t, parsed)
+	unsubscribe := config.FeedbackLink(UnsubscribeAction, "to@mail.com", 0)
+	if expected := "<" + unsubscribe + ">"; header.Get("List-Unsubscribe") != expected {
+		t.Errorf(headerError, expected, header.Get("List-Unsubscribe"))
+	}
+	if expected := "List-Unsubscribe=One-Click"; header.Get("List-Unsubscribe-Post") != expected {
+		t.Errorf(headerError, expected, header.Get("List-Unsubscribe-Post"))
+	}
+	for _, action := range []string{FavoriteAction, LessAction, SnoozeAction} {
+		link := config.FeedbackLink(action, "to@mail.com", quotes[0].Id)
+		if !strings.Contains(parts[0].body, link) {
+			t.Errorf(contentError, link, parts[0].body)
+		}
+	}
+}
+
+func TestConfigMessageWithoutFeedbackLinks(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := feedbackConfig()
+	config.Receiver = []string{"to@mail.com", "other@mail.com"}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	message, _, err := config.message(quotes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	parsed, err := mail.ReadMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, parts := parsed.Header, messageParts(t, parsed)
+	if header.Get("List-Unsubscribe") != "" {
+		t.Errorf(headerError, "", header.Get("List-Unsubscribe"))
+	}
+	if strings.Contains(parts[0].body, "/api/feedback/") {
+		t.Errorf(contentError, "no feedback links", parts[0].body)
+	}
+}
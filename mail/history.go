@@ -0,0 +1,65 @@
+package quote
+
+import (
+	db "quote/db"
+	"sort"
+)
+
+// deliveryCounts returns for each quote how often it has been delivered to
+// the receiver who has seen it the most.
+func deliveryCounts(database *db.Database, receivers []string) (counts map[int]int, err error) {
+	counts = make(map[int]int)
+	for _, receiver := range receivers {
+		var receiverCounts map[int]int
+		receiverCounts, err = database.DeliveryCounts(receiver)
+		if err != nil {
+			return
+		}
+		for quoteId, count := range receiverCounts {
+			if count > counts[quoteId] {
+				counts[quoteId] = count
+			}
+		}
+	}
+	return
+}
+
+// unrepeated selects quotes with `selector` only from the quotes that have
+// been delivered the least often according to `counts`. This way no quote is
+// repeated before all of `quotes` have been delivered, after which a new cycle
+// starts. If the least delivered quotes do not yield a selection (e.g. because
+// of a selector restricting them) the next cycle is used instead.
+func unrepeated(selector QuoteSelector, quotes []db.Quote, count int, counts map[int]int) (selection []db.Quote) {
+	cycles := make(map[int][]db.Quote)
+	for _, quote := range quotes {
+		cycle := counts[quote.Id]
+		cycles[cycle] = append(cycles[cycle], quote)
+	}
+	var order []int
+	for cycle := range cycles {
+		order = append(order, cycle)
+	}
+	sort.Ints(order)
+	for _, cycle := range order {
+		selection = selector.Select(cycles[cycle], count)
+		if len(selection) > 0 {
+			return
+		}
+	}
+	return
+}
+
+// recordDeliveries stores that `quotes` have been delivered to `receivers`
+func recordDeliveries(database *db.Database, receivers []string, quotes []db.Quote) error {
+	return database.WithTx(func(tx *db.Tx) (err error) {
+		for _, receiver := range receivers {
+			for _, quote := range quotes {
+				_, err = tx.NewDelivery(quote.Id, receiver).Commit()
+				if err != nil {
+					return
+				}
+			}
+		}
+		return
+	})
+}
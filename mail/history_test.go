@@ -0,0 +1,105 @@
+package quote
+
+import (
+	db "quote/db"
+	"testing"
+	"time"
+)
+
+func TestUnrepeatedSkipsDeliveredQuotes(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	counts := map[int]int{}
+	for _, quote := range pool[:9] {
+		counts[quote.Id] = 1
+	}
+	// Act
+	selection := unrepeated(RandomSelector{}, pool, 5, counts)
+	// Assert
+	expectedLen := 3
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Fatalf(lenError, expectedLen, actualLen)
+	}
+	for _, quote := range selection {
+		if counts[quote.Id] != 0 {
+			t.Errorf("Quote %d was already delivered", quote.Id)
+		}
+	}
+}
+
+func TestUnrepeatedStartsNewCycle(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	counts := map[int]int{}
+	for _, quote := range pool {
+		counts[quote.Id] = 2
+	}
+	counts[pool[0].Id] = 3
+	// Act
+	selection := unrepeated(RandomSelector{}, pool, 20, counts)
+	// Assert
+	expectedLen := len(pool) - 1
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Fatalf(lenError, expectedLen, actualLen)
+	}
+	assertDistinct(t, selection, quoteId)
+}
+
+func TestSelectQuotesWithoutRepetition(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := Config{Count: 1, Receiver: []string{"to@mail.com"}}
+	seen := make(map[int]bool)
+	// Act
+	for i := 0; i < 2; i += 1 {
+		quotes, err := config.selectQuotes(database)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(quotes) != 1 {
+			t.Fatalf(lenError, 1, len(quotes))
+		}
+		// Assert
+		if seen[quotes[0].Id] {
+			t.Errorf("Quote %d was repeated", quotes[0].Id)
+		}
+		seen[quotes[0].Id] = true
+		err = recordDeliveries(database, config.Receiver, quotes)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSelectQuotesForReview(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	review := database.NewReview(1)
+	review.DueDate = time.Now().AddDate(0, 0, 1)
+	if _, err = review.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	config := Config{Strategy: ReviewStrategy, Receiver: []string{"to@mail.com"}}
+	// Act
+	quotes, err := config.selectQuotes(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(quotes) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(quotes))
+	}
+	if expectedId := 2; quotes[0].Id != expectedId {
+		t.Errorf(contentError, expectedId, quotes[0].Id)
+	}
+}
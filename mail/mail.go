@@ -3,57 +3,307 @@ package quote
 import (
 	"fmt"
 	"log"
-	"math/rand"
-	"net/smtp"
+	"net/mail"
 	db "quote/db"
+	secret "quote/secret"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	Sender   string
-	Password string
+	Sender string
+	// Password of the sender at the SMTP server
+	Password secret.Secret
 	Receiver []string
 	SmtpHost string
 	SmtpPort int
+	// SmtpSecurity is the transport security of the SMTP connection, one of
+	// none, starttls, opportunistic (default) or tls (see sendMail)
+	SmtpSecurity string
+	// SmtpCaFile is the path of PEM encoded certificates used instead of the
+	// system certificates to verify the SMTP server
+	SmtpCaFile string
+	// SmtpAuth is the SMTP authentication mechanism, one of plain (default),
+	// login, cram-md5 or none
+	SmtpAuth string
+	// DkimSelector, DkimDomain and DkimKeyFile configure the DKIM signature
+	// of the mails sent through the SMTP server, they are not signed if any
+	// of them is empty. DkimKeyFile is the path of a PEM encoded RSA or
+	// Ed25519 private key, which determines the signing algorithm.
+	DkimSelector string
+	DkimDomain   string
+	DkimKeyFile  string
+	// The following reminder settings are used for the subscriptions which
+	// are created for Receiver, if there are none in the database yet.
+	//
+	// Schedule is a cron expression or one of the presets daily, weekly or
+	// monthly (see ParseSchedule), defaults to daily
+	Schedule string
+	// Count is the number of quotes per reminder, defaults to 5
+	Count int
+	// Strategy used to select the quotes of a reminder (see NewSelector),
+	// defaults to random
+	Strategy string
+	// TopicId, AuthorId, LanguageId and BookId restrict the quotes of a
+	// reminder to the ones of the topic, author, language or book, unless
+	// they are 0. TopicId and BookId also configure the topic and book
+	// strategy.
+	TopicId    int
+	AuthorId   int
+	LanguageId int
+	BookId     int
+	// TimeZone is the IANA time zone the schedule is evaluated in, e.g.
+	// Europe/Berlin, defaults to the local time zone
+	TimeZone string
+	// SendTime (HH:MM) replaces the time of day of the schedule if set
+	SendTime string
+	// QuietStart and QuietEnd (HH:MM) are the quiet hours, reminders
+	// which would be sent within them are postponed to their end
+	QuietStart string
+	QuietEnd   string
+	// BookQueue holds the ids of the books which follow BookId with the
+	// series strategy
+	BookQueue []int
+	// Quiz blanks out key words of the quotes of a reminder, which are shown
+	// in full after a separator
+	Quiz bool
+	// Budget is the maximum length of the quotes of a reminder together,
+	// which are packed up to it instead of selecting Count quotes, unless it
+	// is 0
+	Budget int
+	// MinLength and MaxLength restrict the quotes of a reminder to the ones
+	// of at least and at most the length, unless they are 0
+	MinLength int
+	MaxLength int
+	// LengthUnit is the unit of Budget, MinLength and MaxLength, one of
+	// chars (default) or words
+	LengthUnit string
+	// Cards attaches a PNG image of each quote of a reminder to the mail
+	// (see QuoteCard)
+	Cards bool
+	// CardBackground and CardForeground are the colors (#rrggbb) of the
+	// quote cards, which default to #f5f0e6 and #333333
+	CardBackground string
+	CardForeground string
+	// CardWidth is the width of the quote cards in pixels, defaults to 800
+	CardWidth int
+	// Subject of the reminder mails, defaults to Quote-reminder
+	Subject string
+	// TextTemplate and HtmlTemplate are paths to text/template and
+	// html/template files replacing the default templates of the mail body
+	TextTemplate string
+	HtmlTemplate string
+	// MaxAttempts is the number of attempts to deliver a reminder before it
+	// is marked as failed, defaults to 5
+	MaxAttempts int
+	// RetryDelay is the number of seconds to wait before the first retry of
+	// a failed delivery, which doubles with every further retry, defaults
+	// to 60
+	RetryDelay int
+	// CatchUp is the policy for the reminders of a subscription which were
+	// missed while the service was not running, one of one (default) to send
+	// a single reminder, all to send a reminder for each missed one (at most
+	// 100) or skip
+	CatchUp string
+	// Notifier configures how reminders are delivered, defaults to SMTP
+	Notifier NotifierConfig
+	// Notifiers configures how the reminders of a recipient are delivered,
+	// overriding Notifier
+	Notifiers map[string]NotifierConfig
+	// FeedbackUrl is the base url of the REST api used for the feedback
+	// links of the reminders, e.g. https://quotes.example.com
+	FeedbackUrl string
+	// FeedbackSecret is the key the feedback links are signed with, no
+	// feedback links are added to the reminders if it or FeedbackUrl is
+	// empty
+	FeedbackSecret secret.Secret
+	// FeedbackExpiry is the number of days the feedback links of a reminder
+	// are valid, defaults to 90
+	FeedbackExpiry int
+	// Keystore is the path of the encrypted keystore the secrets of the
+	// configuration can be loaded from (see LoadSecrets)
+	Keystore string
+	// KeystorePassphrase unlocks the keystore, defaults to the environment
+	// variable QUOTE_KEYSTORE_PASSPHRASE
+	KeystorePassphrase secret.Secret
+	// DigestSchedule is the schedule of the weekly digest with the
+	// statistics of the collection (see ParseSchedule), no digest is sent
+	// if it is empty
+	DigestSchedule string
+	// DigestSubject of the digest mails, defaults to Weekly quote digest
+	DigestSubject string
+	// DigestTextTemplate and DigestHtmlTemplate replace the default
+	// templates of the digest like TextTemplate and HtmlTemplate
+	DigestTextTemplate string
+	DigestHtmlTemplate string
+	// subscriptionId is the id of the subscription the configuration was
+	// created for (see subscription), 0 otherwise
+	subscriptionId int
 }
 
-func (c Config) sendMail(quotes []db.Quote) (err error) {
-	auth := smtp.PlainAuth("", c.Sender, c.Password, c.SmtpHost)
-	err = smtp.SendMail(fmt.Sprintf("%s:%d", c.SmtpHost, c.SmtpPort),
-		auth, c.Sender, c.Receiver, []byte(c.message(quotes)))
-	return
-}
-
-func (c Config) message(quotes []db.Quote) (message string) {
+// message renders the reminder containing `quotes`, which are shown as their
+// quiz version of `clozes` if it is not nil. It returns the mail and its plain
+// text.
+func (c Config) message(quotes []db.Quote, clozes map[int]string) (message, text string, err error) {
+	textTmpl, err := textTemplate(c.TextTemplate, "reminder.txt")
+	if err != nil {
+		return
+	}
+	htmlTmpl, err := htmlTemplate(c.HtmlTemplate, "reminder.html")
+	if err != nil {
+		return
+	}
+	sender, err := mail.ParseAddress(c.Sender)
+	if err != nil {
+		return
+	}
+	var receivers []*mail.Address
+	for _, address := range c.Receiver {
+		var receiver *mail.Address
+		receiver, err = mail.ParseAddress(address)
+		if err != nil {
+			return
+		}
+		receivers = append(receivers, receiver)
+	}
+	subject := c.Subject
+	if subject == "" {
+		subject = defaultSubject
+	}
+	data := reminder{Subject: subject, Date: time.Now(), Quotes: quotes,
+		Quiz: clozes != nil, clozes: clozes, config: c}
+	// feedback links are personal, so they are only added for a single
+	// receiver
+	if len(c.Receiver) == 1 {
+		data.Recipient = c.Receiver[0]
+	}
+	textBody, htmlBody, err := render(textTmpl, htmlTmpl, data)
+	if err != nil {
+		return
+	}
+	var buffer strings.Builder
 	// Set header
-	message += fmt.Sprintf("From: %s\r\n", c.Sender)
-	message += fmt.Sprintf("To: %s\r\n", strings.Join(c.Receiver, " "))
-	message += "Subject: Quote-reminder\r\n\r\n"
+	err = header(&buffer, sender, receivers, subject, data.Date)
+	if err != nil {
+		return
+	}
+	if unsubscribe := data.Unsubscribe(); unsubscribe != "" {
+		fmt.Fprintf(&buffer, "List-Unsubscribe: <%s>\r\n", unsubscribe)
+		fmt.Fprintf(&buffer, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
 	// Set body
-	for _, quote := range quotes {
-		message += fmt.Sprintf("'%s' from '%s' by %s\n",
-			quote.Quote, quote.Book.Title, quote.Book.Author.Name)
+	if c.Cards && len(quotes) > 0 {
+		var cards []attachment
+		if cards, err = c.cards(quotes, clozes); err != nil {
+			return
+		}
+		err = mixed(&buffer, textBody, htmlBody, cards)
+	} else {
+		err = alternative(&buffer, textBody, htmlBody)
 	}
+	message, text = buffer.String(), string(textBody)
 	return
 }
 
-func selectQuotes(database *db.Database) (selection []db.Quote) {
-	quotes, err := database.GetQuotes()
+func (c Config) selectQuotes(database *db.Database) (selection []db.Quote, err error) {
+	selector, err := NewSelector(c.Strategy, c.TopicId, c.BookId)
 	if err != nil {
-		log.Fatal(err)
+		return
+	}
+	if _, err = c.lengthUnit(); err != nil {
+		return
+	}
+	count := c.Count
+	if count <= 0 {
+		count = 5
 	}
-	for i := 0; i < 5; i += 1 {
-		selection = append(selection, quotes[rand.Intn(len(quotes))])
+	// the budget limits the number of quotes instead of the count
+	if c.Budget > 0 {
+		count = unlimited
 	}
+	// a series follows its books regardless of the filters and the
+	// delivery history
+	if c.Strategy == SeriesStrategy {
+		return c.seriesQuotes(database, count)
+	}
+	var quotes []db.Quote
+	if c.Strategy == ReviewStrategy {
+		quotes, err = database.DueQuotes(time.Now())
+	} else {
+		quotes, err = database.GetQuotes()
+	}
+	if err != nil {
+		return
+	}
+	// restrict the quotes to the configured filters, so that the delivery
+	// history only considers the quotes eligible for the reminder
+	var eligible []db.Quote
+	for _, quote := range quotes {
+		if c.eligible(quote) {
+			eligible = append(eligible, quote)
+		}
+	}
+	// quotes due for review are repeated on purpose, so the delivery
+	// history does not apply
+	if c.Strategy == ReviewStrategy {
+		eligible, err = applyFeedback(database, c.Receiver, eligible, nil, time.Now())
+		if err != nil {
+			return
+		}
+		selection = c.pack(selector.Select(eligible, count))
+		return
+	}
+	counts, err := deliveryCounts(database, c.Receiver)
+	if err != nil {
+		return
+	}
+	eligible, err = applyFeedback(database, c.Receiver, eligible, counts, time.Now())
+	if err != nil {
+		return
+	}
+	selection = c.pack(unrepeated(selector, eligible, count, counts))
 	return
 }
 
+func (c Config) eligible(quote db.Quote) bool {
+	book := quote.Book
+	return (c.TopicId == 0 || book.Topic.Id == c.TopicId) &&
+		(c.AuthorId == 0 || book.Author.Id == c.AuthorId) &&
+		(c.LanguageId == 0 || book.Language.Id == c.LanguageId) &&
+		(c.BookId == 0 || book.Id == c.BookId) &&
+		c.fits(quote)
+}
+
 func Service(database *db.Database, config Config) {
-	for range time.Tick(time.Hour * 24) {
-		err := config.sendMail(selectQuotes(database))
-		if err != nil {
-			log.Fatal(err)
+	_, err := textTemplate(config.TextTemplate, "reminder.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = htmlTemplate(config.HtmlTemplate, "reminder.html")
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = seedSubscriptions(database, config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	clock := systemClock{}
+	go config.outboxWorker(database, clock, nil)
+	if config.DigestSchedule != "" {
+		go func() {
+			if err := config.runDigest(database, clock, nil); err != nil {
+				log.Fatal(err)
+			}
+		}()
+	}
+	err = config.runSubscriptions(database, clock, nil, func(subscription db.Subscription, activation time.Time) error {
+		messages, err := config.subscription(subscription).Send(database)
+		if err == nil && len(messages) == 0 {
+			log.Printf("no quotes to send to %s", subscription.Recipient)
 		}
+		return err
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
 }
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"os"
+	"path/filepath"
 	db "quote/db"
 	"strings"
 	"testing"
@@ -52,13 +56,38 @@ func TestSelectQuotes(t *testing.T) {
 		t.Fatal(err)
 	}
 	defer database.Close()
+	config := Config{}
 	// Act
-	selectedQuotes := selectQuotes(database)
+	selectedQuotes, err := config.selectQuotes(database)
 	// Assert
-	expectedLen := 5
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the test database only contains two quotes, which are not repeated
+	expectedLen := 2
 	if actualLen := len(selectedQuotes); actualLen != expectedLen {
 		t.Errorf(lenError, expectedLen, actualLen)
 	}
+	if len(selectedQuotes) == 2 && selectedQuotes[0].Id == selectedQuotes[1].Id {
+		t.Errorf("Quote %d was selected twice", selectedQuotes[0].Id)
+	}
+}
+
+func TestSelectQuotesUnknownStrategy(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := Config{Strategy: "unknown"}
+	// Act
+	_, err = config.selectQuotes(database)
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
 }
 
 func TestConfigMessage(t *testing.T) {
@@ -78,13 +107,172 @@ func TestConfigMessage(t *testing.T) {
 		t.Fatal(err)
 	}
 	// Act
-	actualMessage := config.message(quotes)
+	actualMessage, _, err := config.message(quotes, nil)
 	// Assert
-	expectedMessage := fmt.Sprintf(
-		"From: %s\r\nTo: %s\r\nSubject: Quote-reminder\r\n\r\n",
-		config.Sender, strings.Join(config.Receiver, " "))
-	if !strings.HasPrefix(actualMessage, expectedMessage) {
-		t.Errorf(headerError, expectedMessage, actualMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(actualMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedHeaders := map[string]string{
+		"From":         fmt.Sprintf("<%s>", config.Sender),
+		"To":           fmt.Sprintf("<%s>", strings.Join(config.Receiver, ">, <")),
+		"Subject":      "Quote-reminder",
+		"MIME-Version": "1.0",
+	}
+	for key, expectedHeader := range expectedHeaders {
+		if actualHeader := message.Header.Get(key); actualHeader != expectedHeader {
+			t.Errorf(headerError, expectedHeader, actualHeader)
+		}
+	}
+	for _, key := range []string{"Date", "Message-ID"} {
+		if message.Header.Get(key) == "" {
+			t.Errorf(headerError, key, "")
+		}
+	}
+	parts := messageParts(t, message)
+	expectedTypes := []string{"text/plain", "text/html"}
+	if len(parts) != len(expectedTypes) {
+		t.Fatalf(lenError, len(expectedTypes), len(parts))
+	}
+	for i, expectedType := range expectedTypes {
+		if actualType := parts[i].contentType; actualType != expectedType {
+			t.Errorf(headerError, expectedType, actualType)
+		}
+		for _, quote := range quotes {
+			if !strings.Contains(parts[i].body, quote.Quote) {
+				t.Errorf("%s part does not contain %q:\n%s", expectedType, quote.Quote, parts[i].body)
+			}
+		}
+	}
+}
+
+func TestConfigMessageUtf8(t *testing.T) {
+	// Arrange
+	config := Config{
+		Sender:   "Zitat Erinnerung <from@mail.com>",
+		Receiver: []string{"Jürgen Müller <to@mail.com>"},
+		Subject:  "Grüße aus dem Bücherregal",
+	}
+	quotes := []db.Quote{{Quote: "Über allen Gipfeln ist Ruh"}}
+	// Act
+	actualMessage, _, err := config.message(quotes, nil)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(actualMessage, "\r\n") {
+		for _, r := range line {
+			if r > 127 {
+				t.Fatalf("Message contains a non ascii line: %q", line)
+			}
+		}
+	}
+	message, err := mail.ReadMessage(strings.NewReader(actualMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(message.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != config.Subject {
+		t.Errorf(headerError, config.Subject, subject)
+	}
+	receivers, err := message.Header.AddressList("To")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedName := "Jürgen Müller"; receivers[0].Name != expectedName {
+		t.Errorf(headerError, expectedName, receivers[0].Name)
+	}
+	for _, part := range messageParts(t, message) {
+		if !strings.Contains(part.body, quotes[0].Quote) {
+			t.Errorf("%s part does not contain %q:\n%s", part.contentType, quotes[0].Quote, part.body)
+		}
+	}
+}
+
+func TestConfigMessageCustomTemplate(t *testing.T) {
+	// Arrange
+	textTemplate := filepath.Join(t.TempDir(), "reminder.txt")
+	err := ioutil.WriteFile(textTemplate, []byte("{{len .Quotes}} quotes for today"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := Config{
+		Sender:       "from@mail.com",
+		Receiver:     []string{"to@mail.com"},
+		TextTemplate: textTemplate,
+	}
+	quotes := []db.Quote{{Quote: "Quote1"}, {Quote: "Quote2"}}
+	// Act
+	actualMessage, _, err := config.message(quotes, nil)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(actualMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := messageParts(t, message)
+	if expectedBody := "2 quotes for today"; parts[0].body != expectedBody {
+		t.Errorf(contentError, expectedBody, parts[0].body)
+	}
+}
+
+func TestConfigMessageMissingTemplate(t *testing.T) {
+	// Arrange
+	config := Config{
+		Sender:       "from@mail.com",
+		Receiver:     []string{"to@mail.com"},
+		HtmlTemplate: filepath.Join(t.TempDir(), "missing.html"),
+	}
+	// Act
+	_, _, err := config.message(nil, nil)
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
+}
+
+type messagePart struct {
+	contentType string
+	body        string
+}
+
+// messageParts decodes the parts of a multipart/alternative message
+func messageParts(t *testing.T, message *mail.Message) (parts []messagePart) {
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedType := "multipart/alternative"; mediaType != expectedType {
+		t.Fatalf(headerError, expectedType, mediaType)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// NextPart decodes the quoted-printable encoding
+		body, err := ioutil.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, messagePart{contentType, string(body)})
 	}
 }
 
@@ -104,8 +292,12 @@ func TestSendMail(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	message, _, err := config.message(quotes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	// Act
-	err = config.sendMail(quotes)
+	err = config.sendMail(config.Receiver, []byte(message))
 	// Assert
 	if err == nil {
 		t.Error("Expected an error but got nil")
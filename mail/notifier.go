@@ -0,0 +1,287 @@
+package quote
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	db "quote/db"
+	"strings"
+	"time"
+)
+
+// Names of the notifier backends which can be used in NotifierConfig.Type
+const (
+	SmtpNotifierType    = "smtp"
+	WebhookNotifierType = "webhook"
+	MaildirNotifierType = "maildir"
+	MboxNotifierType    = "mbox"
+	StdoutNotifierType  = "stdout"
+)
+
+// webhookTimeout limits the time a webhook may take to respond, so that a hung
+// endpoint does not block the delivery of the outbox
+var webhookTimeout = 30 * time.Second
+
+// Notification is a reminder which is delivered to a single recipient
+type Notification struct {
+	Sender    string
+	Recipient string
+	Subject   string
+	Date      time.Time
+	// Message is the complete MIME message of the reminder
+	Message []byte
+	// Text is the plain text version of the reminder
+	Text   string
+	Quotes []db.Quote
+}
+
+// Notifier delivers reminders to their recipient.
+type Notifier interface {
+	Notify(notification Notification) error
+}
+
+// NotifierConfig configures the backend used to deliver the reminders of a
+// recipient
+type NotifierConfig struct {
+	// Type of the backend, one of smtp (default), webhook, maildir, mbox or
+	// stdout
+	Type string
+	// Url the webhook posts to
+	Url string
+	// Path of the maildir directory or the mbox file
+	Path string
+}
+
+// notifier creates the Notifier configured for `recipient` in Notifiers,
+// falling back to Notifier and SMTP
+func (c Config) notifier(recipient string) (Notifier, error) {
+	settings, ok := c.Notifiers[recipient]
+	if !ok {
+		settings = c.Notifier
+	}
+	switch settings.Type {
+	case "", SmtpNotifierType:
+		return SmtpNotifier{Config: c}, nil
+	case WebhookNotifierType:
+		if settings.Url == "" {
+			return nil, fmt.Errorf("webhook of %s has no url", recipient)
+		}
+		return WebhookNotifier{Url: settings.Url}, nil
+	case MaildirNotifierType:
+		if settings.Path == "" {
+			return nil, fmt.Errorf("maildir of %s has no path", recipient)
+		}
+		return MaildirNotifier{Path: settings.Path}, nil
+	case MboxNotifierType:
+		if settings.Path == "" {
+			return nil, fmt.Errorf("mbox of %s has no path", recipient)
+		}
+		return MboxNotifier{Path: settings.Path}, nil
+	case StdoutNotifierType:
+		return StdoutNotifier{Writer: os.Stdout}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier %q", settings.Type)
+}
+
+// notification creates the Notification of a message of the outbox. Its
+// text is rendered from the quotes of the message, unless the text was stored
+// with the message. Quotes which have been deleted since are left out.
+func (c Config) notification(database *db.Database, message db.Message) (notification Notification, err error) {
+	notification = Notification{
+		Sender:    c.Sender,
+		Recipient: message.Recipient,
+		Subject:   message.Subject,
+		Date:      message.CreatedDate,
+		Message:   []byte(message.Body),
+		Text:      message.Text,
+	}
+	quoteIds, err := message.Quotes()
+	if err != nil {
+		return
+	}
+	for _, quoteId := range quoteIds {
+		var quote db.Quote
+		quote, err = database.GetQuote(quoteId)
+		if err != nil {
+			return
+		}
+		// a quote may have been deleted since the message was queued
+		if quote.Id == 0 {
+			continue
+		}
+		notification.Quotes = append(notification.Quotes, quote)
+	}
+	if notification.Text != "" {
+		return
+	}
+	text, err := textTemplate(c.TextTemplate, "reminder.txt")
+	if err != nil {
+		return
+	}
+	var buffer bytes.Buffer
+	err = text.Execute(&buffer, reminder{
+		Subject:   notification.Subject,
+		Date:      notification.Date,
+		Quotes:    notification.Quotes,
+		Recipient: notification.Recipient,
+		config:    c,
+	})
+	notification.Text = buffer.String()
+	return
+}
+
+// SmtpNotifier sends reminders as mail using the SMTP settings of Config.
+type SmtpNotifier struct {
+	Config Config
+}
+
+func (n SmtpNotifier) Notify(notification Notification) error {
+	return n.Config.sendMail([]string{notification.Recipient}, notification.Message)
+}
+
+// webhookQuote is a quote of the JSON payload posted by the WebhookNotifier
+type webhookQuote struct {
+	Quote  string
+	Page   int
+	Book   string
+	Author string
+}
+
+// webhookPayload is the JSON payload posted by the WebhookNotifier
+type webhookPayload struct {
+	Recipient string
+	Subject   string
+	Date      time.Time
+	Text      string
+	Quotes    []webhookQuote
+}
+
+// WebhookNotifier posts reminders as JSON to an HTTP endpoint, e.g. the
+// incoming webhook of a chat tool.
+type WebhookNotifier struct {
+	Url string
+	// Client used for the requests, defaults to a client which times out after
+	// webhookTimeout
+	Client *http.Client
+}
+
+func (n WebhookNotifier) Notify(notification Notification) error {
+	payload := webhookPayload{
+		Recipient: notification.Recipient,
+		Subject:   notification.Subject,
+		Date:      notification.Date,
+		Text:      notification.Text,
+		Quotes:    []webhookQuote{},
+	}
+	for _, quote := range notification.Quotes {
+		payload.Quotes = append(payload.Quotes, webhookQuote{
+			Quote:  quote.Quote,
+			Page:   quote.Page,
+			Book:   quote.Book.Title,
+			Author: quote.Book.Author.Name,
+		})
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	response, err := client.Post(n.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook %s responded with %s", n.Url, response.Status)
+	}
+	return nil
+}
+
+// MaildirNotifier stores reminders as new mails of a Maildir directory, which
+// is created if it does not exist.
+type MaildirNotifier struct {
+	Path string
+}
+
+func (n MaildirNotifier) Notify(notification Notification) error {
+	for _, dir := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(n.Path, dir), 0700); err != nil {
+			return err
+		}
+	}
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	// unique file name as described by the maildir specification
+	name := fmt.Sprintf("%d.%d_%s.%s", time.Now().Unix(), os.Getpid(),
+		hex.EncodeToString(random), strings.ReplaceAll(hostname, "/", "_"))
+	tmp := filepath.Join(n.Path, "tmp", name)
+	if err = os.WriteFile(tmp, notification.Message, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(n.Path, "new", name))
+}
+
+// MboxNotifier appends reminders to an mbox file, which is created if it
+// does not exist.
+type MboxNotifier struct {
+	Path string
+}
+
+func (n MboxNotifier) Notify(notification Notification) (err error) {
+	sender := "MAILER-DAEMON"
+	if address, err := mail.ParseAddress(notification.Sender); err == nil {
+		sender = address.Address
+	}
+	file, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "From %s %s\n", sender,
+		notification.Date.UTC().Format(time.ANSIC))
+	body := strings.ReplaceAll(string(notification.Message), "\r\n", "\n")
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		// mboxrd quoting of lines which could be taken as separator
+		if strings.HasPrefix(strings.TrimLeft(line, ">"), "From ") {
+			line = ">" + line
+		}
+		buffer.WriteString(line + "\n")
+	}
+	buffer.WriteString("\n")
+	_, err = file.Write(buffer.Bytes())
+	return
+}
+
+// StdoutNotifier writes the plain text of reminders to Writer, which is
+// os.Stdout when configured in Config.
+type StdoutNotifier struct {
+	Writer io.Writer
+}
+
+func (n StdoutNotifier) Notify(notification Notification) error {
+	_, err := fmt.Fprintf(n.Writer, "To: %s\nSubject: %s\n\n%s\n",
+		notification.Recipient, notification.Subject, notification.Text)
+	return err
+}
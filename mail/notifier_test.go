@@ -0,0 +1,337 @@
+package quote
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	db "quote/db"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testNotification() Notification {
+	return Notification{
+		Sender:    "from@mail.com",
+		Recipient: "to@mail.com",
+		Subject:   "Quote-reminder",
+		Date:      time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		Message:   []byte("Subject: Quote-reminder\r\n\r\nFrom the book\r\n"),
+		Text:      "'quote' from 'title' by author\n",
+		Quotes: []db.Quote{{
+			Id:    1,
+			Quote: "quote",
+			Page:  42,
+			Book:  db.Book{Title: "title", Author: db.Author{Name: "author"}},
+		}},
+	}
+}
+
+func TestNotifierOfRecipient(t *testing.T) {
+	// Arrange
+	config := Config{
+		Notifier: NotifierConfig{Type: StdoutNotifierType},
+		Notifiers: map[string]NotifierConfig{
+			"hook@mail.com": {Type: WebhookNotifierType, Url: "http://localhost"},
+			"box@mail.com":  {Type: MboxNotifierType},
+			"foo@mail.com":  {Type: "foo"},
+		},
+	}
+	// Act
+	webhook, webhookErr := config.notifier("hook@mail.com")
+	stdout, stdoutErr := config.notifier("other@mail.com")
+	_, mboxErr := config.notifier("box@mail.com")
+	_, unknownErr := config.notifier("foo@mail.com")
+	// Assert
+	if webhookErr != nil || stdoutErr != nil {
+		t.Fatal(webhookErr, stdoutErr)
+	}
+	if _, ok := webhook.(WebhookNotifier); !ok {
+		t.Errorf(contentError, WebhookNotifier{}, webhook)
+	}
+	if _, ok := stdout.(StdoutNotifier); !ok {
+		t.Errorf(contentError, StdoutNotifier{}, stdout)
+	}
+	if mboxErr == nil {
+		t.Error("Expected an error for a mbox without path but got nil")
+	}
+	if unknownErr == nil {
+		t.Error("Expected an error for an unknown notifier but got nil")
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	// Arrange
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf(headerError, "application/json", contentType)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	notification := testNotification()
+	// Act
+	err := WebhookNotifier{Url: server.URL}.Notify(notification)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Recipient != notification.Recipient || payload.Text != notification.Text {
+		t.Errorf(contentError, notification, payload)
+	}
+	expected := webhookQuote{Quote: "quote", Page: 42, Book: "title", Author: "author"}
+	if len(payload.Quotes) != 1 || payload.Quotes[0] != expected {
+		t.Errorf(contentError, expected, payload.Quotes)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	// Act
+	err := WebhookNotifier{Url: server.URL}.Notify(testNotification())
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
+}
+
+func TestWebhookNotifierTimeout(t *testing.T) {
+	// Arrange
+	previous := webhookTimeout
+	webhookTimeout = 50 * time.Millisecond
+	defer func() { webhookTimeout = previous }()
+	hung := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	}))
+	defer server.Close()
+	defer close(hung)
+	// Act
+	err := WebhookNotifier{Url: server.URL}.Notify(testNotification())
+	// Assert
+	if err == nil {
+		t.Error("Expected a timeout error but got nil")
+	}
+}
+
+func TestMaildirNotifier(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "Maildir")
+	notification := testNotification()
+	// Act
+	err := MaildirNotifier{Path: path}.Notify(notification)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := os.ReadDir(filepath.Join(path, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(files) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(files))
+	}
+	content, err := os.ReadFile(filepath.Join(path, "new", files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, notification.Message) {
+		t.Errorf(contentError, string(notification.Message), string(content))
+	}
+}
+
+func TestMboxNotifier(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "mbox")
+	notifier := MboxNotifier{Path: path}
+	// Act
+	for i := 0; i < 2; i += 1 {
+		if err := notifier.Notify(testNotification()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Assert
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := "From from@mail.com Thu Mar  4 05:06:07 2021\n" +
+		"Subject: Quote-reminder\n\n>From the book\n\n"
+	if expected := message + message; string(content) != expected {
+		t.Errorf(contentError, expected, string(content))
+	}
+}
+
+func TestStdoutNotifier(t *testing.T) {
+	// Arrange
+	var buffer bytes.Buffer
+	notification := testNotification()
+	// Act
+	err := StdoutNotifier{Writer: &buffer}.Notify(notification)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), notification.Text) {
+		t.Errorf(contentError, notification.Text, buffer.String())
+	}
+}
+
+func TestDeliverOutboxToMaildir(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	path := filepath.Join(t.TempDir(), "Maildir")
+	config := Config{
+		Sender:   "from@mail.com",
+		Receiver: []string{"to@mail.com"},
+		Notifiers: map[string]NotifierConfig{
+			"to@mail.com": {Type: MaildirNotifierType, Path: path},
+		},
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = config.enqueue(database, quotes); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	config.deliverOutbox(database, &fakeClock{now: time.Now().Add(time.Minute)})
+	// Assert
+	files, err := os.ReadDir(filepath.Join(path, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(files) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(files))
+	}
+	messages, err := database.MessagesOfStatus(db.MessageSent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(messages) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(messages))
+	}
+}
+
+func TestDeliverQuizToWebhook(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := database.NewQuote(book)
+	quote.Quote = "Knowledge is power"
+	if quote.Id, err = quote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	subscription := database.NewSubscription("hook@mail.com")
+	subscription.Quiz = true
+	config := Config{
+		Sender: "from@mail.com",
+		Notifiers: map[string]NotifierConfig{
+			"hook@mail.com": {Type: WebhookNotifierType, Url: server.URL},
+		},
+	}.subscription(subscription)
+	if _, err = config.enqueue(database, []db.Quote{quote}); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	config.deliverOutbox(database, &fakeClock{now: time.Now().Add(time.Minute)})
+	// Assert
+	quiz := strings.Index(payload.Text, "_________ is power")
+	full := strings.Index(payload.Text, "Knowledge is power")
+	if quiz < 0 || full < quiz {
+		t.Errorf("webhook text does not show the quiz before the full quote:\n%s", payload.Text)
+	}
+}
+
+func TestDeliverDeletedQuoteToWebhook(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := database.NewQuote(book)
+	quote.Quote = "Knowledge is power"
+	if quote.Id, err = quote.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	kept, err := database.GetQuote(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	config := Config{
+		Sender:   "from@mail.com",
+		Receiver: []string{"hook@mail.com"},
+		Notifiers: map[string]NotifierConfig{
+			"hook@mail.com": {Type: WebhookNotifierType, Url: server.URL},
+		},
+	}
+	if _, err = config.enqueue(database, []db.Quote{quote, kept}); err != nil {
+		t.Fatal(err)
+	}
+	if err = quote.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	config.deliverOutbox(database, &fakeClock{now: time.Now().Add(time.Minute)})
+	// Assert
+	messages, err := database.MessagesOfStatus(db.MessageSent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(messages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(messages))
+	}
+	if expectedLen := 1; len(payload.Quotes) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(payload.Quotes))
+	}
+	if payload.Quotes[0].Quote != kept.Quote {
+		t.Errorf(contentError, kept.Quote, payload.Quotes[0].Quote)
+	}
+}
@@ -0,0 +1,183 @@
+package quote
+
+import (
+	"log"
+	db "quote/db"
+	"sync"
+	"time"
+)
+
+// Default retry settings of the outbox
+const (
+	defaultMaxAttempts = 5
+	defaultRetryDelay  = 60 // in seconds
+)
+
+// outboxLock ensures that a pending message is only delivered once, even if
+// the outbox is processed concurrently
+var outboxLock sync.Mutex
+
+// enqueue renders the reminder containing `quotes` and stores it in the
+// outbox for each receiver, returning the ids of the stored messages
+func (c Config) enqueue(database *db.Database, quotes []db.Quote) (ids []int, err error) {
+	subject := c.Subject
+	if subject == "" {
+		subject = defaultSubject
+	}
+	var quoteIds []int
+	for _, quote := range quotes {
+		quoteIds = append(quoteIds, quote.Id)
+	}
+	clozes, err := c.clozes(database, quotes)
+	if err != nil {
+		return
+	}
+	// the reminder is queued for all receivers or none of them, so that a
+	// retry does not send it twice
+	err = database.WithTx(func(tx *db.Tx) error {
+		for _, receiver := range c.Receiver {
+			// each receiver gets a message of their own, as it contains
+			// their personal feedback links
+			settings := c
+			settings.Receiver = []string{receiver}
+			// the text is stored with the mail, as the quiz is only
+			// known when the reminder is rendered
+			body, text, err := settings.message(quotes, clozes)
+			if err != nil {
+				return err
+			}
+			message := tx.NewMessage(receiver, subject, body, quoteIds)
+			message.Text = text
+			message.SubscriptionId = c.subscriptionId
+			id, err := message.Commit()
+			if err != nil {
+				return err
+			}
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	if err != nil {
+		ids = nil
+	}
+	return
+}
+
+// notify delivers `message` with the notifier of its recipient
+func (c Config) notify(database *db.Database, message db.Message) error {
+	notifier, err := c.notifier(message.Recipient)
+	if err != nil {
+		return err
+	}
+	notification, err := c.notification(database, message)
+	if err != nil {
+		return err
+	}
+	return notifier.Notify(notification)
+}
+
+// deliveredQuotes records the deliveries of the quotes contained in the sent
+// `message`
+func deliveredQuotes(database *db.Database, message db.Message) error {
+	quoteIds, err := message.Quotes()
+	if err != nil {
+		return err
+	}
+	var quotes []db.Quote
+	for _, quoteId := range quoteIds {
+		quotes = append(quotes, db.Quote{Id: quoteId})
+	}
+	return recordDeliveries(database, []string{message.Recipient}, quotes)
+}
+
+// retryDelay returns the time to wait after the `attempts`th failed attempt
+func (c Config) retryDelay(attempts int) time.Duration {
+	delay := c.RetryDelay
+	if delay <= 0 {
+		delay = defaultRetryDelay
+	}
+	return time.Duration(delay) * time.Second << uint(attempts-1)
+}
+
+// deliverOutbox attempts to deliver all pending messages of the outbox which
+// are due. Messages which could not be delivered are retried with an
+// exponential backoff until MaxAttempts is reached, after which they are
+// marked as failed.
+func (c Config) deliverOutbox(database *db.Database, clock Clock) {
+	outboxLock.Lock()
+	defer outboxLock.Unlock()
+	messages, err := database.PendingMessages(clock.Now())
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, message := range messages {
+		c.deliver(database, clock, message)
+	}
+}
+
+// deliverMessages attempts to deliver the messages `ids` of the outbox which
+// are still pending, regardless of when they are due. It returns the messages
+// with their new status.
+func (c Config) deliverMessages(database *db.Database, clock Clock, ids []int) (messages []db.Message, err error) {
+	outboxLock.Lock()
+	defer outboxLock.Unlock()
+	for _, id := range ids {
+		var message db.Message
+		message, err = database.GetMessage(id)
+		if err != nil {
+			return
+		}
+		if message.Status == db.MessagePending {
+			message = c.deliver(database, clock, message)
+		}
+		messages = append(messages, message)
+	}
+	return
+}
+
+// deliver attempts to deliver the pending `message` and stores its new
+// status, which is returned with the message
+func (c Config) deliver(database *db.Database, clock Clock, message db.Message) db.Message {
+	maxAttempts := c.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	err := c.notify(database, message)
+	message.Attempts += 1
+	if err == nil {
+		message.Status = db.MessageSent
+		message.LastError = ""
+		if err := deliveredQuotes(database, message); err != nil {
+			log.Println(err)
+		}
+		if err := advanceSeries(database, message); err != nil {
+			log.Println(err)
+		}
+	} else {
+		log.Printf("delivery of message %d to %s failed: %v",
+			message.Id, message.Recipient, err)
+		message.LastError = err.Error()
+		if message.Attempts >= maxAttempts {
+			message.Status = db.MessageFailed
+		} else {
+			message.NextAttempt = clock.Now().Add(c.retryDelay(message.Attempts))
+		}
+	}
+	if _, err = message.Commit(); err != nil {
+		log.Println(err)
+	}
+	return message
+}
+
+// outboxWorker delivers the outbox every pollInterval until `stop` is closed
+func (c Config) outboxWorker(database *db.Database, clock Clock, stop <-chan struct{}) {
+	for {
+		c.deliverOutbox(database, clock)
+		select {
+		case <-stop:
+			return
+		case <-clock.After(pollInterval):
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package quote
+
+import (
+	db "quote/db"
+	"testing"
+	"time"
+)
+
+func TestEnqueue(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := Config{
+		Sender:   "from@mail.com",
+		Receiver: []string{"first@mail.com", "second@mail.com"},
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	_, err = config.enqueue(database, quotes)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := database.MessagesOfStatus(db.MessagePending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 2; len(messages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(messages))
+	}
+	for i, message := range messages {
+		if message.Recipient != config.Receiver[i] {
+			t.Errorf(contentError, config.Receiver[i], message.Recipient)
+		}
+		if message.Subject != defaultSubject {
+			t.Errorf(contentError, defaultSubject, message.Subject)
+		}
+		ids, err := message.Quotes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != len(quotes) {
+			t.Errorf(lenError, len(quotes), len(ids))
+		}
+	}
+}
+
+func TestDeliverOutbox(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	server := newFakeSmtp(t, fakeSmtpOptions{Mechanisms: []string{"PLAIN"}})
+	config := Config{
+		Sender:   "from@mail.com",
+		Receiver: []string{"to@mail.com"},
+		SmtpHost: "127.0.0.1",
+		SmtpPort: server.Port(),
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = config.enqueue(database, quotes); err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{now: time.Now()}
+	// Act
+	config.deliverOutbox(database, clock)
+	// Assert
+	mails := server.Mails()
+	if expectedLen := 1; len(mails) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(mails))
+	}
+	if mails[0].To[0] != "to@mail.com" {
+		t.Errorf(contentError, "to@mail.com", mails[0].To[0])
+	}
+	messages, err := database.MessagesOfStatus(db.MessageSent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(messages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(messages))
+	}
+	counts, err := database.DeliveryCounts("to@mail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, quote := range quotes {
+		if counts[quote.Id] != 1 {
+			t.Errorf(contentError, 1, counts[quote.Id])
+		}
+	}
+}
+
+func TestDeliverOutboxRetries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	server := newFakeSmtp(t, fakeSmtpOptions{Reject: true})
+	config := Config{
+		Sender:      "from@mail.com",
+		Receiver:    []string{"to@mail.com"},
+		SmtpHost:    "127.0.0.1",
+		SmtpPort:    server.Port(),
+		MaxAttempts: 3,
+		RetryDelay:  10,
+	}
+	quotes, err := database.GetQuotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = config.enqueue(database, quotes); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Truncate(time.Second)
+	clock := &fakeClock{now: start}
+	// Act
+	config.deliverOutbox(database, clock)
+	// Assert
+	messages, err := database.MessagesOfStatus(db.MessagePending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(messages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(messages))
+	}
+	message := messages[0]
+	if message.Attempts != 1 || message.LastError == "" {
+		t.Errorf(contentError, "1 attempt with an error", message)
+	}
+	if expected := start.Add(10 * time.Second); !message.NextAttempt.Equal(expected) {
+		t.Errorf(contentError, expected, message.NextAttempt)
+	}
+	// the message is not retried before its next attempt
+	config.deliverOutbox(database, clock)
+	if message, _ = database.GetMessage(message.Id); message.Attempts != 1 {
+		t.Errorf(contentError, 1, message.Attempts)
+	}
+	// the delay doubles with every attempt
+	clock.now = start.Add(10 * time.Second)
+	config.deliverOutbox(database, clock)
+	if message, _ = database.GetMessage(message.Id); !message.NextAttempt.Equal(clock.now.Add(20 * time.Second)) {
+		t.Errorf(contentError, clock.now.Add(20*time.Second), message.NextAttempt)
+	}
+	// the last attempt marks the message as failed
+	clock.now = start.Add(30 * time.Second)
+	config.deliverOutbox(database, clock)
+	if message, _ = database.GetMessage(message.Id); message.Status != db.MessageFailed {
+		t.Errorf(contentError, db.MessageFailed, message.Status)
+	}
+	if expectedLen := 0; len(server.Mails()) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(server.Mails()))
+	}
+}
@@ -0,0 +1,54 @@
+package quote
+
+import (
+	db "quote/db"
+)
+
+// Preview is a rendered reminder which has not been sent
+type Preview struct {
+	Subject string
+	// Body is the complete MIME message of the reminder
+	Body   string
+	Quotes []db.Quote
+}
+
+// Preview selects and renders the next reminder of the configuration without
+// sending it or recording any deliveries
+func (c Config) Preview(database *db.Database) (preview Preview, err error) {
+	quotes, err := c.selectQuotes(database)
+	if err != nil {
+		return
+	}
+	clozes, err := c.clozes(database, quotes)
+	if err != nil {
+		return
+	}
+	body, _, err := c.message(quotes, clozes)
+	if err != nil {
+		return
+	}
+	preview.Subject = c.Subject
+	if preview.Subject == "" {
+		preview.Subject = defaultSubject
+	}
+	preview.Body = body
+	preview.Quotes = quotes
+	return
+}
+
+// Send selects a reminder of the configuration and delivers it immediately
+// through the outbox, other messages of the outbox are left to its worker. It
+// returns the messages of the outbox containing the reminder, which are still
+// pending if their delivery failed. Nothing is sent if there are no quotes to
+// select.
+func (c Config) Send(database *db.Database) (messages []db.Message, err error) {
+	quotes, err := c.selectQuotes(database)
+	if err != nil || len(quotes) == 0 {
+		return
+	}
+	ids, err := c.enqueue(database, quotes)
+	if err != nil {
+		return
+	}
+	return c.deliverMessages(database, systemClock{}, ids)
+}
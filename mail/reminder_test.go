@@ -0,0 +1,125 @@
+package quote
+
+import (
+	db "quote/db"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := Config{Sender: "from@mail.com", Receiver: []string{"to@mail.com"}}
+	// Act
+	preview, err := config.Preview(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Subject != defaultSubject {
+		t.Errorf(contentError, defaultSubject, preview.Subject)
+	}
+	if expectedLen := 2; len(preview.Quotes) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(preview.Quotes))
+	}
+	if preview.Body == "" {
+		t.Error("Expected a body but got none")
+	}
+	// nothing is sent or recorded
+	messages, err := database.GetMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliveries, err := database.GetDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 || len(deliveries) != 0 {
+		t.Errorf(lenError, 0, len(messages)+len(deliveries))
+	}
+}
+
+func TestSend(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := Config{
+		Sender:       "from@mail.com",
+		Receiver:     []string{"to@mail.com"},
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}
+	// Act
+	messages, err := config.Send(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(messages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(messages))
+	}
+	if messages[0].Status != db.MessageSent {
+		t.Errorf(contentError, db.MessageSent, messages[0].Status)
+	}
+	if expectedLen := 1; len(server.Mails()) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(server.Mails()))
+	}
+	deliveries, err := database.GetDeliveries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 2; len(deliveries) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(deliveries))
+	}
+}
+
+func TestSendLeavesOtherMessages(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := Config{
+		Sender:       "from@mail.com",
+		Receiver:     []string{"to@mail.com"},
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}
+	otherId, err := database.NewMessage("other@mail.com", "Other", "body", nil).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	messages, err := config.Send(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 1; len(messages) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(messages))
+	}
+	if expectedLen := 1; len(server.Mails()) != expectedLen {
+		t.Errorf(lenError, expectedLen, len(server.Mails()))
+	}
+	other, err := database.GetMessage(otherId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Status != db.MessagePending || other.Attempts != 0 {
+		t.Errorf(contentError, db.MessagePending, other.Status)
+	}
+}
@@ -0,0 +1,44 @@
+package quote
+
+import (
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// textMessage renders a plain text mail to `recipient`
+func (c Config) textMessage(recipient, subject, text string) (message string, err error) {
+	sender, err := mail.ParseAddress(c.Sender)
+	if err != nil {
+		return
+	}
+	receiver, err := mail.ParseAddress(recipient)
+	if err != nil {
+		return
+	}
+	var buffer strings.Builder
+	err = header(&buffer, sender, []*mail.Address{receiver}, subject, time.Now())
+	if err != nil {
+		return
+	}
+	buffer.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	encoder := quotedprintable.NewWriter(&buffer)
+	if _, err = encoder.Write([]byte(text)); err != nil {
+		return
+	}
+	err = encoder.Close()
+	message = buffer.String()
+	return
+}
+
+// Reply sends a plain text mail to `recipient` through the SMTP server, e.g.
+// to answer a mail received by the application
+func (c Config) Reply(recipient, subject, text string) error {
+	message, err := c.textMessage(recipient, subject, text)
+	if err != nil {
+		return err
+	}
+	return c.sendMail([]string{recipient}, []byte(message))
+}
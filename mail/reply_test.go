@@ -0,0 +1,46 @@
+package quote
+
+import (
+	"io/ioutil"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestReply(t *testing.T) {
+	// Arrange
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := Config{
+		Sender:       "quotes@mail.com",
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}
+	// Act
+	err := config.Reply("from@mail.com", "Quote added", "Your quote was added")
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	mails := server.Mails()
+	if len(mails) != 1 {
+		t.Fatalf(lenError, 1, len(mails))
+	}
+	if len(mails[0].To) != 1 || mails[0].To[0] != "from@mail.com" {
+		t.Errorf(contentError, "from@mail.com", mails[0].To)
+	}
+	message, err := mail.ReadMessage(strings.NewReader(mails[0].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject := message.Header.Get("Subject"); subject != "Quote added" {
+		t.Errorf(headerError, "Quote added", subject)
+	}
+	body, err := ioutil.ReadAll(message.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "Your quote was added") {
+		t.Errorf(contentError, "Your quote was added", string(body))
+	}
+}
@@ -0,0 +1,286 @@
+package quote
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes at which points in time a reminder should be sent.
+type Schedule interface {
+	// Next returns the first activation of the Schedule strictly after `t`
+	// in the location of `t`. A zero time is returned if there is no such
+	// activation.
+	Next(t time.Time) time.Time
+}
+
+// named presets which can be used instead of a cron expression
+var presets = map[string]string{
+	"daily":    "0 0 * * *",
+	"weekly":   "0 0 * * 0",
+	"monthly":  "0 0 1 * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type field struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteField = field{"minute", 0, 59, nil}
+	hourField   = field{"hour", 0, 23, nil}
+	domField    = field{"day of month", 1, 31, nil}
+	monthField  = field{"month", 1, 12, monthNames}
+	dowField    = field{"day of week", 0, 7, dayNames}
+)
+
+// cronSchedule is a parsed five field cron expression. Each field is stored
+// as a bit set of the allowed values.
+type cronSchedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// true if the corresponding field was restricted, which changes how day
+	// of month and day of week are combined
+	domRestricted bool
+	dowRestricted bool
+}
+
+// ParseSchedule parses either a named preset (daily, weekly, monthly) or a
+// cron expression with the five fields minute, hour, day of month, month and
+// day of week. Fields support `*`, values, ranges (`1-5`), steps (`*/15`) and
+// lists (`1,3,5`). Months and days of week can also be given by their three
+// letter english names (e.g. `mon-fri`).
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if preset, ok := presets[strings.ToLower(spec)]; ok {
+		spec = preset
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q: expected 5 fields but got %d", spec, len(fields))
+	}
+	var schedule cronSchedule
+	var err error
+	if schedule.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// 7 is an alias for sunday
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	// like in cron a field starting with * (e.g. */2) is not restricted
+	schedule.domRestricted = !strings.HasPrefix(fields[2], "*")
+	schedule.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return schedule, nil
+}
+
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: value %d out of range [%d, %d]", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func (f field) parse(s string) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, part[i+1:])
+			}
+			part = part[:i]
+		}
+		low, high := f.min, f.max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			if low, err = f.value(bounds[0]); err != nil {
+				return
+			}
+			if high, err = f.value(bounds[1]); err != nil {
+				return
+			}
+			if low > high {
+				return 0, fmt.Errorf("%s: invalid range %q", f.name, part)
+			}
+		default:
+			if low, err = f.value(part); err != nil {
+				return
+			}
+			if step == 1 {
+				high = low
+			}
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom := has(s.dom, t.Day())
+	dow := has(s.dow, int(t.Weekday()))
+	// like in cron a day matches if either field matches, when both
+	// fields are restricted
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func (s cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	// the schedule is evaluated on the wall clock of `loc`, which is
+	// represented in UTC to be unaffected by daylight saving time changes
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	for {
+		wall = s.nextWall(wall)
+		if wall.IsZero() {
+			return wall
+		}
+		// a wall clock time which occurs twice is only activated once
+		if next := localTime(wall, loc); next.After(t) {
+			return next
+		}
+	}
+}
+
+// nextWall returns the first wall clock time after `t` matching the schedule,
+// with both represented in UTC
+func (s cronSchedule) nextWall(t time.Time) time.Time {
+	t = t.Add(time.Minute)
+	// if there is no match within five years the schedule can never match
+	// (e.g. 30th of february)
+	limit := t.Year() + 5
+	for t.Year() <= limit {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// localTime returns the first point in time at which the clocks in `loc` show
+// `wall` (represented in UTC). A wall clock time which is skipped by a
+// daylight saving time change is moved forward by the length of the gap,
+// e.g. 02:30 becomes 03:30.
+func localTime(wall time.Time, loc *time.Location) time.Time {
+	guess := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+	_, offsetBefore := guess.Add(-12 * time.Hour).Zone()
+	_, offsetAfter := guess.Add(12 * time.Hour).Zone()
+	before := wall.Add(-time.Duration(offsetBefore) * time.Second).In(loc)
+	after := wall.Add(-time.Duration(offsetAfter) * time.Second).In(loc)
+	shows := func(t time.Time) bool {
+		return t.Year() == wall.Year() && t.YearDay() == wall.YearDay() &&
+			t.Hour() == wall.Hour() && t.Minute() == wall.Minute()
+	}
+	if shows(before) || !shows(after) {
+		// the earlier occurrence, or the time moved forward by a gap
+		return before
+	}
+	return after
+}
+
+// Clock provides the current time and a way to wait for some time to pass.
+// It allows tests to fast-forward time instead of actually waiting.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Scheduler executes jobs at the activations of a Schedule, using Clock to
+// determine the current time.
+type Scheduler struct {
+	Schedule Schedule
+	Clock    Clock
+}
+
+// Run executes `job` at each activation of the Schedule until `stop` is
+// closed. The activation time is passed to `job`.
+func (s Scheduler) Run(stop <-chan struct{}, job func(time.Time)) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		next := s.Schedule.Next(s.Clock.Now())
+		if next.IsZero() {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-s.Clock.After(next.Sub(s.Clock.Now())):
+			job(next)
+		}
+	}
+}
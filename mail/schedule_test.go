@@ -0,0 +1,126 @@
+package quote
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	nextError = "Next activation of %q does not match\nexpected: %v\nactual: %v\n"
+)
+
+// fakeClock fast-forwards to the requested point in time instead of waiting
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	channel := make(chan time.Time, 1)
+	channel <- c.now
+	return channel
+}
+
+func TestParseInvalidSchedules(t *testing.T) {
+	// Arrange
+	specs := []string{"", "yearly", "* * * *", "60 * * * *", "* 24 * * *",
+		"* * 0 * *", "* * * 13 *", "* * * * 8", "5-1 * * * *", "*/0 * * * *",
+		"* * * foo *"}
+	for _, spec := range specs {
+		// Act
+		_, err := ParseSchedule(spec)
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %q but got nil", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Arrange
+	// 2022-01-05 is a wednesday
+	from := time.Date(2022, 1, 5, 10, 15, 0, 0, time.UTC)
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"daily", time.Date(2022, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2022, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{"monthly", time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2022, 1, 5, 10, 16, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2022, 1, 5, 10, 20, 0, 0, time.UTC)},
+		{"30 7 * * mon-fri", time.Date(2022, 1, 6, 7, 30, 0, 0, time.UTC)},
+		{"30 7 * * sat,sun", time.Date(2022, 1, 8, 7, 30, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2022, 1, 9, 12, 0, 0, 0, time.UTC)},
+		{"0 8 29 feb *", time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC)},
+		// day of month and day of week are combined with or
+		{"0 9 1 * fri", time.Date(2022, 1, 7, 9, 0, 0, 0, time.UTC)},
+		// unless one of them starts with *, then both have to match
+		{"0 9 */2 * mon", time.Date(2022, 1, 17, 9, 0, 0, 0, time.UTC)},
+		{"0 9 1 * */2", time.Date(2022, 2, 1, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Act
+		actual := schedule.Next(from)
+		// Assert
+		if !actual.Equal(c.expected) {
+			t.Errorf(nextError, c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestScheduleNeverMatches(t *testing.T) {
+	// Arrange
+	schedule, err := ParseSchedule("0 0 30 feb *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	next := schedule.Next(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	// Assert
+	if !next.IsZero() {
+		t.Errorf("Expected no activation but got %v", next)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	// Arrange
+	schedule, err := ParseSchedule("30 7 * * mon-fri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2022-01-07 is a friday
+	clock := &fakeClock{now: time.Date(2022, 1, 7, 8, 0, 0, 0, time.UTC)}
+	scheduler := Scheduler{Schedule: schedule, Clock: clock}
+	stop := make(chan struct{})
+	var activations []time.Time
+	// Act
+	scheduler.Run(stop, func(activation time.Time) {
+		activations = append(activations, activation)
+		if len(activations) == 3 {
+			close(stop)
+		}
+	})
+	// Assert
+	expected := []time.Time{
+		time.Date(2022, 1, 10, 7, 30, 0, 0, time.UTC),
+		time.Date(2022, 1, 11, 7, 30, 0, 0, time.UTC),
+		time.Date(2022, 1, 12, 7, 30, 0, 0, time.UTC),
+	}
+	if len(activations) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(activations))
+	}
+	for i, activation := range activations {
+		if !activation.Equal(expected[i]) {
+			t.Errorf(nextError, "30 7 * * mon-fri", expected[i], activation)
+		}
+	}
+}
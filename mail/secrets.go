@@ -0,0 +1,53 @@
+package quote
+
+import (
+	"errors"
+	"os"
+	secret "quote/secret"
+)
+
+// KeystorePassphraseEnv is the environment variable holding the passphrase of
+// the keystore, unless KeystorePassphrase is configured
+const KeystorePassphraseEnv = "QUOTE_KEYSTORE_PASSPHRASE"
+
+// Passphrase returns the passphrase of the keystore, which is loaded from
+// KeystorePassphrase or KeystorePassphraseEnv
+func (c Config) Passphrase() (string, error) {
+	passphrase := c.KeystorePassphrase
+	if passphrase.IsZero() {
+		return os.Getenv(KeystorePassphraseEnv), nil
+	}
+	if passphrase.InKeystore() {
+		return "", errors.New("the keystore passphrase cannot be stored in the keystore")
+	}
+	err := passphrase.Resolve(nil)
+	return passphrase.Value(), err
+}
+
+// LoadSecrets loads the secrets of the configuration and `other` secrets from
+// their sources, opening the keystore if any of them is stored in it
+func (c *Config) LoadSecrets(other ...*secret.Secret) error {
+	secrets := append([]*secret.Secret{&c.Password, &c.FeedbackSecret}, other...)
+	var keystore secret.Keystore
+	for _, s := range secrets {
+		if !s.InKeystore() || keystore != nil {
+			continue
+		}
+		if c.Keystore == "" {
+			return errors.New("secrets are stored in the keystore, but no keystore is configured")
+		}
+		passphrase, err := c.Passphrase()
+		if err != nil {
+			return err
+		}
+		if keystore, err = secret.OpenKeystore(c.Keystore, passphrase); err != nil {
+			return err
+		}
+	}
+	for _, s := range secrets {
+		if err := s.Resolve(keystore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package quote
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	secret "quote/secret"
+	"testing"
+)
+
+func TestLoadSecrets(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	keystore := filepath.Join(dir, "keystore.json")
+	if err := (secret.Keystore{"smtp": "topsecret"}).Save(keystore, "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	passphrase := filepath.Join(dir, "passphrase")
+	if err := ioutil.WriteFile(passphrase, []byte("passphrase\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("QUOTE_TEST_FEEDBACK_SECRET", "change-me")
+	configJson := `{
+		"password": {"keystore": "smtp"},
+		"feedbackSecret": {"env": "QUOTE_TEST_FEEDBACK_SECRET"},
+		"keystore": "` + keystore + `",
+		"keystorePassphrase": {"file": "` + passphrase + `"}
+	}`
+	var config Config
+	if err := json.Unmarshal([]byte(configJson), &config); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err := config.LoadSecrets()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "topsecret", config.Password.Value(); actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+	if expected, actual := "change-me", config.FeedbackSecret.Value(); actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
+
+func TestLoadSecretsWithoutKeystore(t *testing.T) {
+	// Arrange
+	var config Config
+	if err := json.Unmarshal([]byte(`{"password": {"keystore": "smtp"}}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	err := config.LoadSecrets()
+	// Assert
+	if err == nil {
+		t.Error("Expected an error without keystore but got nil")
+	}
+}
+
+func TestPassphraseFromEnvironment(t *testing.T) {
+	// Arrange
+	t.Setenv(KeystorePassphraseEnv, "passphrase")
+	// Act
+	actual, err := Config{}.Passphrase()
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "passphrase"; actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
@@ -0,0 +1,255 @@
+package quote
+
+import (
+	"fmt"
+	"math/rand"
+	db "quote/db"
+	"sort"
+	"time"
+)
+
+// QuoteSelector chooses the quotes of a reminder from a pool of quotes.
+type QuoteSelector interface {
+	// Select returns up to `count` quotes of `quotes`, without modifying
+	// `quotes`
+	Select(quotes []db.Quote, count int) []db.Quote
+}
+
+// Names of the selection strategies which can be used in Config.Strategy
+const (
+	RandomStrategy          = "random"
+	TopicStrategy           = "topic"
+	DistinctTopicsStrategy  = "topics"
+	DistinctAuthorsStrategy = "authors"
+	BookStrategy            = "book"
+	ReviewStrategy          = "review"
+	OnThisDayStrategy       = "onthisday"
+	SeriesStrategy          = "series"
+)
+
+// NewSelector creates the QuoteSelector for `strategy`. `topicId` and
+// `bookId` are only used by the TopicStrategy and BookStrategy respectively,
+// with 0 meaning a random topic or book. The SeriesStrategy requires a
+// `bookId` to start with.
+func NewSelector(strategy string, topicId, bookId int) (QuoteSelector, error) {
+	switch strategy {
+	case "", RandomStrategy:
+		return RandomSelector{}, nil
+	case TopicStrategy:
+		return TopicSelector{TopicId: topicId}, nil
+	case DistinctTopicsStrategy:
+		return DistinctTopicsSelector{}, nil
+	case DistinctAuthorsStrategy:
+		return DistinctAuthorsSelector{}, nil
+	case BookStrategy:
+		return BookSelector{BookId: bookId}, nil
+	case ReviewStrategy:
+		return DueSelector{}, nil
+	case OnThisDayStrategy:
+		return OnThisDaySelector{}, nil
+	case SeriesStrategy:
+		if bookId == 0 {
+			return nil, fmt.Errorf("selection strategy %q requires a book", strategy)
+		}
+		return SeriesSelector{BookId: bookId}, nil
+	}
+	return nil, fmt.Errorf("unknown selection strategy %q", strategy)
+}
+
+// shuffled returns a shuffled copy of `quotes`
+func shuffled(quotes []db.Quote) []db.Quote {
+	res := make([]db.Quote, len(quotes))
+	for i, j := range rand.Perm(len(quotes)) {
+		res[i] = quotes[j]
+	}
+	return res
+}
+
+func limit(quotes []db.Quote, count int) []db.Quote {
+	if len(quotes) > count {
+		return quotes[:count]
+	}
+	return quotes
+}
+
+// distinct picks one random quote for each distinct key, in random order
+func distinct(quotes []db.Quote, count int, key func(db.Quote) int) (selection []db.Quote) {
+	seen := make(map[int]bool)
+	for _, quote := range shuffled(quotes) {
+		if len(selection) == count {
+			break
+		}
+		if k := key(quote); !seen[k] {
+			seen[k] = true
+			selection = append(selection, quote)
+		}
+	}
+	return
+}
+
+// RandomSelector selects quotes uniformly at random without replacement.
+type RandomSelector struct{}
+
+func (RandomSelector) Select(quotes []db.Quote, count int) []db.Quote {
+	return limit(shuffled(quotes), count)
+}
+
+// TopicSelector selects random quotes which all belong to one topic. If
+// TopicId is 0 the topic of a random quote is used.
+type TopicSelector struct {
+	TopicId int
+}
+
+func (s TopicSelector) Select(quotes []db.Quote, count int) []db.Quote {
+	topicId := s.TopicId
+	if topicId == 0 && len(quotes) > 0 {
+		topicId = quotes[rand.Intn(len(quotes))].Book.Topic.Id
+	}
+	var candidates []db.Quote
+	for _, quote := range quotes {
+		if quote.Book.Topic.Id == topicId {
+			candidates = append(candidates, quote)
+		}
+	}
+	return RandomSelector{}.Select(candidates, count)
+}
+
+// DistinctTopicsSelector selects one random quote of different topics.
+type DistinctTopicsSelector struct{}
+
+func (DistinctTopicsSelector) Select(quotes []db.Quote, count int) []db.Quote {
+	return distinct(quotes, count, func(quote db.Quote) int {
+		return quote.Book.Topic.Id
+	})
+}
+
+// DistinctAuthorsSelector selects one random quote of different authors.
+type DistinctAuthorsSelector struct{}
+
+func (DistinctAuthorsSelector) Select(quotes []db.Quote, count int) []db.Quote {
+	return distinct(quotes, count, func(quote db.Quote) int {
+		return quote.Book.Author.Id
+	})
+}
+
+// BookSelector selects all quotes of one book ordered by their page,
+// regardless of the requested count. If BookId is 0 the book of a random
+// quote is used.
+type BookSelector struct {
+	BookId int
+}
+
+func (s BookSelector) Select(quotes []db.Quote, count int) (selection []db.Quote) {
+	bookId := s.BookId
+	if bookId == 0 && len(quotes) > 0 {
+		bookId = quotes[rand.Intn(len(quotes))].Book.Id
+	}
+	for _, quote := range quotes {
+		if quote.Book.Id == bookId {
+			selection = append(selection, quote)
+		}
+	}
+	sort.SliceStable(selection, func(i, j int) bool {
+		return selection[i].Page < selection[j].Page
+	})
+	return
+}
+
+// DueSelector selects the first quotes of a pool of quotes which are due for
+// review (see db.DueQuotes), i.e. the ones which are overdue the longest.
+type DueSelector struct{}
+
+func (DueSelector) Select(quotes []db.Quote, count int) []db.Quote {
+	return limit(quotes, count)
+}
+
+// SeriesSelector selects the next `count` quotes of a book in page order,
+// which follow the position of the quote with the id Cursor on the page Page.
+// Quotes on the same page are ordered by their id, so that the position is
+// kept even if the quote of the Cursor has been deleted or moved. It starts
+// with the first quote of the book if Page and Cursor are 0.
+type SeriesSelector struct {
+	BookId int
+	Page   int
+	Cursor int
+}
+
+func (s SeriesSelector) Select(quotes []db.Quote, count int) (selection []db.Quote) {
+	if s.BookId == 0 {
+		return nil
+	}
+	book := BookSelector{BookId: s.BookId}.Select(quotes, count)
+	sort.SliceStable(book, func(i, j int) bool {
+		return book[i].Page < book[j].Page ||
+			book[i].Page == book[j].Page && book[i].Id < book[j].Id
+	})
+	for _, quote := range book {
+		if after(quote, s.Page, s.Cursor) {
+			selection = append(selection, quote)
+		}
+	}
+	return limit(selection, count)
+}
+
+// after tells whether `quote` comes after the quote with the id `cursor` on
+// the page `page` of a series
+func after(quote db.Quote, page, cursor int) bool {
+	return quote.Page > page || quote.Page == page && quote.Id > cursor
+}
+
+// onThisDayWindow is the default number of days a quote selected by the
+// OnThisDaySelector may have been recorded before or after the calendar day
+const onThisDayWindow = 7
+
+// OnThisDaySelector selects quotes which have been recorded on the calendar
+// day of Date in earlier years. If there are too few of them the quotes
+// recorded closest to the calendar day, at most Window days apart, are
+// selected as well. A zero Date means today and a zero Window 7 days.
+type OnThisDaySelector struct {
+	Date   time.Time
+	Window int
+}
+
+// calendarDistance returns the number of days between the calendar days of
+// `a` and `b` regardless of their year
+func calendarDistance(a, b time.Time) int {
+	distance := -1
+	for _, year := range []int{b.Year() - 1, b.Year(), b.Year() + 1} {
+		day := time.Date(year, a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+		reference := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+		days := int(day.Sub(reference).Hours() / 24)
+		if days < 0 {
+			days = -days
+		}
+		if distance < 0 || days < distance {
+			distance = days
+		}
+	}
+	return distance
+}
+
+func (s OnThisDaySelector) Select(quotes []db.Quote, count int) []db.Quote {
+	date := s.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	window := s.Window
+	if window <= 0 {
+		window = onThisDayWindow
+	}
+	var candidates []db.Quote
+	distances := make(map[int]int)
+	for _, quote := range shuffled(quotes) {
+		if quote.RecordDate.IsZero() || quote.RecordDate.Year() >= date.Year() {
+			continue
+		}
+		if distance := calendarDistance(quote.RecordDate, date); distance <= window {
+			distances[quote.Id] = distance
+			candidates = append(candidates, quote)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distances[candidates[i].Id] < distances[candidates[j].Id]
+	})
+	return limit(candidates, count)
+}
@@ -0,0 +1,257 @@
+package quote
+
+import (
+	db "quote/db"
+	"testing"
+	"time"
+)
+
+const (
+	duplicateError = "Selection contains %v twice: %v\n"
+	contentError   = "content had not the expected value\nexpected: %v\nactual: %v\n"
+)
+
+// testPool creates 12 quotes of 4 books, where books 1 and 2 share topic and
+// author 1, book 3 has topic and author 2 and book 4 topic and author 3.
+func testPool() (quotes []db.Quote) {
+	books := []db.Book{
+		{Id: 1, Topic: db.Topic{Id: 1}, Author: db.Author{Id: 1}},
+		{Id: 2, Topic: db.Topic{Id: 1}, Author: db.Author{Id: 1}},
+		{Id: 3, Topic: db.Topic{Id: 2}, Author: db.Author{Id: 2}},
+		{Id: 4, Topic: db.Topic{Id: 3}, Author: db.Author{Id: 3}},
+	}
+	for i := 0; i < 12; i += 1 {
+		quotes = append(quotes, db.Quote{
+			Id:   i + 1,
+			Book: books[i%len(books)],
+			Page: 100 - i,
+		})
+	}
+	return
+}
+
+func assertDistinct(t *testing.T, selection []db.Quote, key func(db.Quote) int) {
+	seen := make(map[int]bool)
+	for _, quote := range selection {
+		k := key(quote)
+		if seen[k] {
+			t.Errorf(duplicateError, k, selection)
+		}
+		seen[k] = true
+	}
+}
+
+func quoteId(quote db.Quote) int {
+	return quote.Id
+}
+
+func TestNewSelector(t *testing.T) {
+	// Arrange
+	strategies := []string{"", RandomStrategy, TopicStrategy,
+		DistinctTopicsStrategy, DistinctAuthorsStrategy, BookStrategy,
+		ReviewStrategy, OnThisDayStrategy}
+	for _, strategy := range strategies {
+		// Act
+		_, err := NewSelector(strategy, 0, 0)
+		// Assert
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", strategy, err)
+		}
+	}
+	if _, err := NewSelector("unknown", 0, 0); err == nil {
+		t.Error("Expected an error but got nil")
+	}
+	if _, err := NewSelector(SeriesStrategy, 0, 0); err == nil {
+		t.Error("Expected an error for a series without a book but got nil")
+	}
+}
+
+func TestRandomSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// Act
+	selection := RandomSelector{}.Select(pool, 5)
+	// Assert
+	expectedLen := 5
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Errorf(lenError, expectedLen, actualLen)
+	}
+	assertDistinct(t, selection, quoteId)
+}
+
+func TestRandomSelectorSmallPool(t *testing.T) {
+	// Arrange
+	pool := testPool()[:3]
+	// Act
+	selection := RandomSelector{}.Select(pool, 5)
+	// Assert
+	expectedLen := 3
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Errorf(lenError, expectedLen, actualLen)
+	}
+	assertDistinct(t, selection, quoteId)
+}
+
+func TestTopicSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// Act
+	selection := TopicSelector{TopicId: 1}.Select(pool, 5)
+	// Assert
+	expectedLen := 5
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Errorf(lenError, expectedLen, actualLen)
+	}
+	assertDistinct(t, selection, quoteId)
+	for _, quote := range selection {
+		if quote.Book.Topic.Id != 1 {
+			t.Errorf(contentError, 1, quote.Book.Topic.Id)
+		}
+	}
+}
+
+func TestRandomTopicSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// Act
+	selection := TopicSelector{}.Select(pool, 5)
+	// Assert
+	if len(selection) == 0 {
+		t.Fatal("Expected a selection but got none")
+	}
+	for _, quote := range selection {
+		if quote.Book.Topic.Id != selection[0].Book.Topic.Id {
+			t.Errorf(contentError, selection[0].Book.Topic.Id, quote.Book.Topic.Id)
+		}
+	}
+}
+
+func TestDistinctTopicsSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// Act
+	selection := DistinctTopicsSelector{}.Select(pool, 5)
+	// Assert
+	expectedLen := 3
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Errorf(lenError, expectedLen, actualLen)
+	}
+	assertDistinct(t, selection, func(quote db.Quote) int {
+		return quote.Book.Topic.Id
+	})
+}
+
+func TestDistinctAuthorsSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// Act
+	selection := DistinctAuthorsSelector{}.Select(pool, 2)
+	// Assert
+	expectedLen := 2
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Errorf(lenError, expectedLen, actualLen)
+	}
+	assertDistinct(t, selection, func(quote db.Quote) int {
+		return quote.Book.Author.Id
+	})
+}
+
+func TestBookSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// Act
+	selection := BookSelector{BookId: 2}.Select(pool, 1)
+	// Assert
+	expectedLen := 3
+	if actualLen := len(selection); actualLen != expectedLen {
+		t.Fatalf(lenError, expectedLen, actualLen)
+	}
+	for i, quote := range selection {
+		if quote.Book.Id != 2 {
+			t.Errorf(contentError, 2, quote.Book.Id)
+		}
+		if i > 0 && selection[i-1].Page > quote.Page {
+			t.Errorf("Quotes are not ordered by page: %v", selection)
+		}
+	}
+}
+
+func TestSeriesSelector(t *testing.T) {
+	// Arrange
+	pool := testPool()
+	// the quotes of book 2 in page order
+	cases := map[[2]int][]int{
+		{0, 0}:   {10, 6},
+		{91, 10}: {6, 2},
+		{95, 6}:  {2},
+		{99, 2}:  nil,
+		// the position of a quote which was deleted or moved is kept
+		{93, 8}: {6, 2},
+	}
+	for position, expected := range cases {
+		// Act
+		selection := SeriesSelector{BookId: 2, Page: position[0], Cursor: position[1]}.Select(pool, 2)
+		// Assert
+		if len(selection) != len(expected) {
+			t.Fatalf(lenError, len(expected), len(selection))
+		}
+		for i, quote := range selection {
+			if quote.Id != expected[i] {
+				t.Errorf(contentError, expected[i], quote.Id)
+			}
+		}
+	}
+}
+
+func TestCalendarDistance(t *testing.T) {
+	// Arrange
+	tests := []struct {
+		a, b     time.Time
+		expected int
+	}{
+		{time.Date(1999, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(1999, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), 3},
+		{time.Date(1999, 12, 30, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), 3},
+		{time.Date(1999, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 30, 0, 0, 0, 0, time.UTC), 3},
+	}
+	for _, test := range tests {
+		// Act
+		actual := calendarDistance(test.a, test.b)
+		// Assert
+		if actual != test.expected {
+			t.Errorf(contentError, test.expected, actual)
+		}
+	}
+}
+
+func TestOnThisDaySelector(t *testing.T) {
+	// Arrange
+	date := time.Date(2021, 3, 4, 7, 30, 0, 0, time.UTC)
+	pool := []db.Quote{
+		{Id: 1, RecordDate: time.Date(2015, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{Id: 2, RecordDate: time.Date(2019, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{Id: 3, RecordDate: time.Date(2018, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{Id: 4, RecordDate: time.Date(2017, 2, 20, 0, 0, 0, 0, time.UTC)},
+		{Id: 5, RecordDate: time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{Id: 6, RecordDate: time.Date(2016, 3, 9, 0, 0, 0, 0, time.UTC)},
+	}
+	// Act
+	exact := OnThisDaySelector{Date: date}.Select(pool, 2)
+	nearby := OnThisDaySelector{Date: date}.Select(pool, 5)
+	// Assert
+	if expectedLen := 2; len(exact) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(exact))
+	}
+	for _, quote := range exact {
+		if quote.Id != 1 && quote.Id != 3 {
+			t.Errorf(contentError, "quote 1 or 3", quote.Id)
+		}
+	}
+	// quote 4 is too far apart and quote 5 has been recorded this year
+	if expectedLen := 4; len(nearby) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(nearby))
+	}
+	if nearby[2].Id != 2 || nearby[3].Id != 6 {
+		t.Errorf("Quotes are not ordered by their distance: %v", nearby)
+	}
+}
@@ -0,0 +1,136 @@
+package quote
+
+import (
+	db "quote/db"
+)
+
+// seriesPosition is the position of a series, the book it is at with the
+// page and id of the quote sent last, and the books queued after it
+type seriesPosition struct {
+	bookId int
+	queue  []int
+	page   int
+	cursor int
+}
+
+// advance moves the position after the quote `last`. The series only moves
+// forward, a quote before the position or of a book which is neither the
+// current nor a queued one keeps it unchanged. It returns whether the
+// position moved.
+func (p *seriesPosition) advance(last db.Quote) bool {
+	if last.Id == 0 {
+		return false
+	}
+	if last.Book.Id == p.bookId {
+		if !after(last, p.page, p.cursor) {
+			return false
+		}
+	} else {
+		// drop the books which have been finished from the queue
+		finished := -1
+		for i, bookId := range p.queue {
+			if bookId == last.Book.Id {
+				finished = i
+				break
+			}
+		}
+		if finished < 0 {
+			return false
+		}
+		p.bookId, p.queue = last.Book.Id, p.queue[finished+1:]
+	}
+	p.page, p.cursor = last.Page, last.Id
+	return true
+}
+
+// lastQuote returns the last quote of `message` which still exists, a quote
+// with the id 0 if there is none
+func lastQuote(database *db.Database, message db.Message) (last db.Quote, err error) {
+	quoteIds, err := message.Quotes()
+	if err != nil {
+		return
+	}
+	for i := len(quoteIds) - 1; i >= 0 && last.Id == 0; i-- {
+		if last, err = database.GetQuote(quoteIds[i]); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// seriesQuotes selects the next `count` quotes of the series of books of the
+// configuration. The series continues where the last reminder of its
+// subscription stopped, including the reminders which are still pending in
+// the outbox, and moves on to the next book of the queue once a book is
+// finished. There are no quotes once all books are finished.
+func (c Config) seriesQuotes(database *db.Database, count int) (quotes []db.Quote, err error) {
+	position := seriesPosition{bookId: c.BookId, queue: c.BookQueue}
+	if c.subscriptionId != 0 {
+		var subscription db.Subscription
+		if subscription, err = database.GetSubscription(c.subscriptionId); err != nil {
+			return
+		}
+		if position.queue, err = subscription.Queue(); err != nil {
+			return
+		}
+		position.bookId, position.page, position.cursor = subscription.BookId, subscription.CursorPage, subscription.Cursor
+		// the series only advances when a reminder is delivered, so the
+		// reminders which are not delivered yet are skipped here
+		var pending []db.Message
+		if pending, err = database.PendingMessagesOf(c.subscriptionId); err != nil {
+			return
+		}
+		for _, message := range pending {
+			var last db.Quote
+			if last, err = lastQuote(database, message); err != nil {
+				return
+			}
+			position.advance(last)
+		}
+	}
+	for {
+		var book []db.Quote
+		if book, err = database.RelatedQuotesOfBook(position.bookId); err != nil {
+			return
+		}
+		selector := SeriesSelector{BookId: position.bookId, Page: position.page, Cursor: position.cursor}
+		// the quotes of a series are not skipped because of their length
+		quotes = c.packSeries(selector.Select(book, count))
+		if len(quotes) > 0 || len(position.queue) == 0 {
+			return
+		}
+		position = seriesPosition{bookId: position.queue[0], queue: position.queue[1:]}
+	}
+}
+
+// advanceSeries records that the quotes of the series `message` have been
+// delivered, so that the next reminder of its subscription continues after
+// them. The series only moves forward, a message which is delivered late
+// does not take it back to an earlier position.
+func advanceSeries(database *db.Database, message db.Message) error {
+	if message.SubscriptionId == 0 {
+		return nil
+	}
+	subscription, err := database.GetSubscription(message.SubscriptionId)
+	if err != nil || subscription.Strategy != SeriesStrategy {
+		return err
+	}
+	queue, err := subscription.Queue()
+	if err != nil {
+		return err
+	}
+	last, err := lastQuote(database, message)
+	if err != nil {
+		return err
+	}
+	position := seriesPosition{
+		bookId: subscription.BookId,
+		queue:  queue,
+		page:   subscription.CursorPage,
+		cursor: subscription.Cursor,
+	}
+	if !position.advance(last) {
+		return nil
+	}
+	return database.SubscriptionProgress(subscription.Id, position.bookId, position.queue, position.cursor, position.page)
+}
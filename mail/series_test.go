@@ -0,0 +1,223 @@
+package quote
+
+import (
+	db "quote/db"
+	"testing"
+	"time"
+)
+
+// seriesSubscription adds a prologue and an epilogue to Book1 and returns
+// the ids of the quotes by their text together with a series subscription,
+// which sends 2 quotes of Book1 and then Book2
+func seriesSubscription(t *testing.T, database *db.Database) (map[string]int, db.Subscription) {
+	book, err := database.GetBook(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Quote1 is on page 69 of Book1 and Quote2 on page 69 of Book2
+	pages := map[string]int{"Prologue": 1, "Epilogue": 420}
+	ids := make(map[string]int)
+	for text, page := range pages {
+		quote := database.NewQuote(book)
+		quote.Quote = text
+		quote.Page = page
+		if ids[text], err = quote.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	subscription := database.NewSubscription("to@mail.com")
+	subscription.Strategy = SeriesStrategy
+	subscription.Count = 2
+	subscription.BookId = 1
+	subscription.SetQueue([]int{2})
+	if subscription.Id, err = subscription.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return ids, subscription
+}
+
+func TestSendSeries(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	ids, subscription := seriesSubscription(t, database)
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := Config{
+		Sender:       "from@mail.com",
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}.subscription(subscription)
+	expected := [][]int{{ids["Prologue"], 1}, {ids["Epilogue"]}, {2}, nil}
+	for _, expectedIds := range expected {
+		// Act
+		messages, err := config.Send(database)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expectedIds == nil {
+			if len(messages) != 0 {
+				t.Errorf(lenError, 0, len(messages))
+			}
+			continue
+		}
+		if len(messages) != 1 {
+			t.Fatalf(lenError, 1, len(messages))
+		}
+		actualIds, err := messages[0].Quotes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actualIds) != len(expectedIds) {
+			t.Fatalf(lenError, len(expectedIds), len(actualIds))
+		}
+		for i, id := range actualIds {
+			if id != expectedIds[i] {
+				t.Errorf(contentError, expectedIds[i], id)
+			}
+		}
+	}
+	stored, err := database.GetSubscription(subscription.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.BookId != 2 || stored.BookQueue != "" || stored.Cursor != 2 || stored.CursorPage != 69 {
+		t.Errorf(contentError, "book 2 finished", stored)
+	}
+}
+
+func TestSendSeriesDeletedCursor(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	ids, subscription := seriesSubscription(t, database)
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := Config{
+		Sender:       "from@mail.com",
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}.subscription(subscription)
+	if _, err = config.Send(database); err != nil {
+		t.Fatal(err)
+	}
+	quote, err := database.GetQuote(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = quote.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	messages, err := config.Send(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf(lenError, 1, len(messages))
+	}
+	actualIds, err := messages[0].Quotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actualIds) != 1 || actualIds[0] != ids["Epilogue"] {
+		t.Errorf(contentError, []int{ids["Epilogue"]}, actualIds)
+	}
+}
+
+func TestSendSeriesAdvancesOnDelivery(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	ids, subscription := seriesSubscription(t, database)
+	server := newFakeSmtp(t, fakeSmtpOptions{Reject: true})
+	config := Config{
+		Sender:       "from@mail.com",
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}.subscription(subscription)
+	// Act
+	messages, err := config.Send(database)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0].Status == db.MessageSent {
+		t.Fatalf(contentError, "an undelivered message", messages)
+	}
+	stored, err := database.GetSubscription(subscription.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.BookId != 1 || stored.Cursor != 0 || stored.CursorPage != 0 {
+		t.Errorf(contentError, "series at the beginning", stored)
+	}
+	// Act
+	config.SmtpPort = newFakeSmtp(t, fakeSmtpOptions{}).Port()
+	config.deliverOutbox(database, &fakeClock{now: time.Now().Add(time.Hour)})
+	// Assert
+	stored, err = database.GetSubscription(subscription.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.BookId != 1 || stored.Cursor != 1 || stored.CursorPage != 69 {
+		t.Errorf(contentError, []int{ids["Prologue"], 1}, stored)
+	}
+}
+
+func TestSendSeriesSkipsPendingQuotes(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	ids, subscription := seriesSubscription(t, database)
+	server := newFakeSmtp(t, fakeSmtpOptions{Reject: true})
+	config := Config{
+		Sender:       "from@mail.com",
+		SmtpHost:     "127.0.0.1",
+		SmtpPort:     server.Port(),
+		SmtpSecurity: SecurityNone,
+	}.subscription(subscription)
+	expected := [][]int{{ids["Prologue"], 1}, {ids["Epilogue"]}}
+	for _, expectedIds := range expected {
+		// Act
+		messages, err := config.Send(database)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != 1 || messages[0].Status != db.MessagePending {
+			t.Fatalf(contentError, "a pending message", messages)
+		}
+		actualIds, err := messages[0].Quotes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actualIds) != len(expectedIds) {
+			t.Fatalf(lenError, len(expectedIds), len(actualIds))
+		}
+		for i := range expectedIds {
+			if actualIds[i] != expectedIds[i] {
+				t.Errorf(contentError, expectedIds, actualIds)
+			}
+		}
+	}
+}
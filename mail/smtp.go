@@ -0,0 +1,176 @@
+package quote
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transport security modes which can be used in Config.SmtpSecurity
+const (
+	// SecurityNone never encrypts the connection
+	SecurityNone = "none"
+	// SecurityStartTLS upgrades the connection with STARTTLS and fails if
+	// the server does not support it
+	SecurityStartTLS = "starttls"
+	// SecurityOpportunistic upgrades the connection with STARTTLS if the
+	// server supports it
+	SecurityOpportunistic = "opportunistic"
+	// SecurityTLS connects with implicit TLS, usually on port 465
+	SecurityTLS = "tls"
+)
+
+// Authentication mechanisms which can be used in Config.SmtpAuth
+const (
+	AuthPlain   = "plain"
+	AuthLogin   = "login"
+	AuthCramMD5 = "cram-md5"
+	AuthNone    = "none"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// tlsConfig creates the configuration used to verify the SMTP server
+func (c Config) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{ServerName: c.SmtpHost}
+	if c.SmtpCaFile == "" {
+		return config, nil
+	}
+	certificates, err := os.ReadFile(c.SmtpCaFile)
+	if err != nil {
+		return nil, err
+	}
+	config.RootCAs = x509.NewCertPool()
+	if !config.RootCAs.AppendCertsFromPEM(certificates) {
+		return nil, fmt.Errorf("no certificates found in %s", c.SmtpCaFile)
+	}
+	return config, nil
+}
+
+// auth creates the smtp.Auth of the configured mechanism for `username`, nil
+// meaning no authentication
+func (c Config) auth(username string) (smtp.Auth, error) {
+	switch c.SmtpAuth {
+	case "", AuthPlain:
+		return smtp.PlainAuth("", username, c.Password.Value(), c.SmtpHost), nil
+	case AuthLogin:
+		return loginAuth{username, c.Password.Value(), c.SmtpHost}, nil
+	case AuthCramMD5:
+		return smtp.CRAMMD5Auth(username, c.Password.Value()), nil
+	case AuthNone:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown SMTP authentication %q", c.SmtpAuth)
+}
+
+// sendMail sends `message` to `receivers` using the transport security and
+// authentication mechanism of the configuration, signed with DKIM if it is
+// configured
+func (c Config) sendMail(receivers []string, message []byte) (err error) {
+	message, err = c.dkimSign(message)
+	if err != nil {
+		return
+	}
+	sender, err := mail.ParseAddress(c.Sender)
+	if err != nil {
+		return
+	}
+	auth, err := c.auth(sender.Address)
+	if err != nil {
+		return
+	}
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return
+	}
+	address := net.JoinHostPort(c.SmtpHost, fmt.Sprint(c.SmtpPort))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	var conn net.Conn
+	switch c.SmtpSecurity {
+	case SecurityTLS:
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	case "", SecurityNone, SecurityStartTLS, SecurityOpportunistic:
+		conn, err = dialer.Dial("tcp", address)
+	default:
+		err = fmt.Errorf("unknown SMTP security %q", c.SmtpSecurity)
+	}
+	if err != nil {
+		return
+	}
+	client, err := smtp.NewClient(conn, c.SmtpHost)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	defer client.Close()
+	if c.SmtpSecurity != SecurityNone && c.SmtpSecurity != SecurityTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err = client.StartTLS(tlsConfig); err != nil {
+				return
+			}
+		} else if c.SmtpSecurity == SecurityStartTLS {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok && auth != nil {
+		if err = client.Auth(auth); err != nil {
+			return
+		}
+	}
+	if err = client.Mail(sender.Address); err != nil {
+		return
+	}
+	for _, receiver := range receivers {
+		if err = client.Rcpt(receiver); err != nil {
+			return
+		}
+	}
+	data, err := client.Data()
+	if err != nil {
+		return
+	}
+	if _, err = data.Write(message); err != nil {
+		return
+	}
+	if err = data.Close(); err != nil {
+		return
+	}
+	return client.Quit()
+}
+
+// loginAuth implements the LOGIN authentication mechanism, which like
+// smtp.PlainAuth only sends the credentials over TLS or to localhost.
+type loginAuth struct {
+	username, password, host string
+}
+
+func (a loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	local := a.host == "localhost" || a.host == "127.0.0.1" || a.host == "::1"
+	if !server.TLS && !local {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+}
@@ -0,0 +1,178 @@
+package quote
+
+import (
+	secret "quote/secret"
+	"testing"
+)
+
+func smtpConfig(server *fakeSmtp) Config {
+	return Config{
+		Sender:   "from@mail.com",
+		Password: secret.New("topsecret"),
+		SmtpHost: "127.0.0.1",
+		SmtpPort: server.Port(),
+	}
+}
+
+func TestSendMailSecurity(t *testing.T) {
+	// Arrange
+	serverTls, caFile := testCertificate(t)
+	tests := []struct {
+		security    string
+		options     fakeSmtpOptions
+		expectedTls bool
+	}{
+		{SecurityNone, fakeSmtpOptions{TLS: serverTls}, false},
+		{SecurityStartTLS, fakeSmtpOptions{TLS: serverTls}, true},
+		{SecurityOpportunistic, fakeSmtpOptions{TLS: serverTls}, true},
+		{SecurityOpportunistic, fakeSmtpOptions{}, false},
+		{"", fakeSmtpOptions{TLS: serverTls}, true},
+		{SecurityTLS, fakeSmtpOptions{TLS: serverTls, Implicit: true}, true},
+	}
+	for _, test := range tests {
+		server := newFakeSmtp(t, test.options)
+		config := smtpConfig(server)
+		config.SmtpSecurity = test.security
+		config.SmtpCaFile = caFile
+		config.SmtpAuth = AuthNone
+		// Act
+		err := config.sendMail([]string{"to@mail.com"}, []byte("Subject: test\r\n\r\nbody\r\n"))
+		// Assert
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.security, err)
+			continue
+		}
+		mails := server.Mails()
+		if len(mails) != 1 {
+			t.Errorf(lenError, 1, len(mails))
+			continue
+		}
+		if mails[0].TLS != test.expectedTls {
+			t.Errorf(contentError, test.expectedTls, mails[0].TLS)
+		}
+		if mails[0].Data != "Subject: test\r\n\r\nbody\r\n" {
+			t.Errorf(contentError, "Subject: test\r\n\r\nbody\r\n", mails[0].Data)
+		}
+	}
+}
+
+func TestSendMailStartTLSRequired(t *testing.T) {
+	// Arrange
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := smtpConfig(server)
+	config.SmtpSecurity = SecurityStartTLS
+	// Act
+	err := config.sendMail([]string{"to@mail.com"}, []byte("body\r\n"))
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
+	if len(server.Mails()) != 0 {
+		t.Errorf(lenError, 0, len(server.Mails()))
+	}
+}
+
+func TestSendMailUntrustedCertificate(t *testing.T) {
+	// Arrange
+	serverTls, _ := testCertificate(t)
+	server := newFakeSmtp(t, fakeSmtpOptions{TLS: serverTls, Implicit: true})
+	config := smtpConfig(server)
+	config.SmtpSecurity = SecurityTLS
+	// Act
+	err := config.sendMail([]string{"to@mail.com"}, []byte("body\r\n"))
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
+}
+
+func TestSendMailInvalidCaFile(t *testing.T) {
+	// Arrange
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	config := smtpConfig(server)
+	config.SmtpCaFile = testConfig
+	// Act
+	err := config.sendMail([]string{"to@mail.com"}, []byte("body\r\n"))
+	// Assert
+	if err == nil {
+		t.Error("Expected an error but got nil")
+	}
+}
+
+func TestSendMailAuth(t *testing.T) {
+	// Arrange
+	serverTls, caFile := testCertificate(t)
+	tests := []struct {
+		auth     string
+		expected string
+	}{
+		{"", "PLAIN"},
+		{AuthPlain, "PLAIN"},
+		{AuthLogin, "LOGIN"},
+		{AuthCramMD5, "CRAM-MD5"},
+		{AuthNone, ""},
+	}
+	for _, test := range tests {
+		server := newFakeSmtp(t, fakeSmtpOptions{
+			TLS:        serverTls,
+			Mechanisms: []string{"PLAIN", "LOGIN", "CRAM-MD5"},
+			Username:   "from@mail.com",
+			Password:   "topsecret",
+		})
+		config := smtpConfig(server)
+		config.SmtpCaFile = caFile
+		config.SmtpAuth = test.auth
+		// Act
+		err := config.sendMail([]string{"to@mail.com"}, []byte("body\r\n"))
+		// Assert
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.auth, err)
+			continue
+		}
+		mails := server.Mails()
+		if len(mails) != 1 {
+			t.Errorf(lenError, 1, len(mails))
+			continue
+		}
+		if mails[0].Auth != test.expected {
+			t.Errorf(contentError, test.expected, mails[0].Auth)
+		}
+	}
+}
+
+func TestSendMailWrongPassword(t *testing.T) {
+	// Arrange
+	for _, auth := range []string{AuthPlain, AuthLogin, AuthCramMD5} {
+		server := newFakeSmtp(t, fakeSmtpOptions{
+			Mechanisms: []string{"PLAIN", "LOGIN", "CRAM-MD5"},
+			Username:   "from@mail.com",
+			Password:   "other",
+		})
+		config := smtpConfig(server)
+		config.SmtpSecurity = SecurityNone
+		config.SmtpAuth = auth
+		// Act
+		err := config.sendMail([]string{"to@mail.com"}, []byte("body\r\n"))
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %q but got nil", auth)
+		}
+	}
+}
+
+func TestSendMailUnknownSettings(t *testing.T) {
+	// Arrange
+	server := newFakeSmtp(t, fakeSmtpOptions{})
+	unknownSecurity := smtpConfig(server)
+	unknownSecurity.SmtpSecurity = "ssl"
+	unknownAuth := smtpConfig(server)
+	unknownAuth.SmtpAuth = "xoauth2"
+	for _, config := range []Config{unknownSecurity, unknownAuth} {
+		// Act
+		err := config.sendMail([]string{"to@mail.com"}, []byte("body\r\n"))
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %v but got nil", config)
+		}
+	}
+}
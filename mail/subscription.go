@@ -0,0 +1,194 @@
+package quote
+
+import (
+	"fmt"
+	"log"
+	db "quote/db"
+	"strings"
+	"time"
+)
+
+// pollInterval is the maximum time between two checks of the subscriptions,
+// so that subscriptions created or changed in the meantime are picked up
+const pollInterval = time.Minute
+
+// subscription returns the Config for the reminders of `subscription`,
+// using the mail server settings of `c`
+func (c Config) subscription(subscription db.Subscription) Config {
+	c.subscriptionId = subscription.Id
+	c.Receiver = []string{subscription.Recipient}
+	c.Schedule = subscription.Schedule
+	c.Count = subscription.Count
+	c.Strategy = subscription.Strategy
+	c.TopicId = subscription.TopicId
+	c.AuthorId = subscription.AuthorId
+	c.LanguageId = subscription.LanguageId
+	c.BookId = subscription.BookId
+	c.TimeZone = subscription.TimeZone
+	c.SendTime = subscription.SendTime
+	c.QuietStart = subscription.QuietStart
+	c.QuietEnd = subscription.QuietEnd
+	c.Quiz = subscription.Quiz
+	// invalid queues are rejected when the subscription is stored
+	c.BookQueue, _ = subscription.Queue()
+	return c
+}
+
+// seedSubscriptions creates a subscription for each receiver of `config`
+// with the reminder settings of `config`, if there are no subscriptions yet.
+// This keeps configurations working which were written before subscriptions
+// were stored in the database.
+func seedSubscriptions(database *db.Database, config Config) (err error) {
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil || len(subscriptions) > 0 {
+		return
+	}
+	for _, receiver := range config.Receiver {
+		subscription := database.NewSubscription(receiver)
+		if config.Schedule != "" {
+			subscription.Schedule = config.Schedule
+		}
+		if config.Count > 0 {
+			subscription.Count = config.Count
+		}
+		if config.Strategy != "" {
+			subscription.Strategy = config.Strategy
+		}
+		subscription.TopicId = config.TopicId
+		subscription.AuthorId = config.AuthorId
+		subscription.LanguageId = config.LanguageId
+		subscription.BookId = config.BookId
+		subscription.TimeZone = config.TimeZone
+		subscription.SendTime = config.SendTime
+		subscription.QuietStart = config.QuietStart
+		subscription.QuietEnd = config.QuietEnd
+		subscription.Quiz = config.Quiz
+		subscription.SetQueue(config.BookQueue)
+		if _, err = subscription.Commit(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Catch-up policies for the reminders missed while the service was not running
+const (
+	// CatchUpOne sends a single reminder for all missed activations
+	CatchUpOne = "one"
+	// CatchUpAll sends a reminder for each missed activation
+	CatchUpAll = "all"
+	// CatchUpSkip sends no reminders for missed activations
+	CatchUpSkip = "skip"
+)
+
+// maxMissed limits the number of missed activations which are caught up with
+// CatchUpAll, so that a frequent schedule does not flood the recipient
+const maxMissed = 100
+
+// catchUpPolicy returns the catch-up policy of `c`, which defaults to
+// CatchUpOne
+func (c Config) catchUpPolicy() (policy string, err error) {
+	switch policy = strings.ToLower(c.CatchUp); policy {
+	case "":
+		return CatchUpOne, nil
+	case CatchUpOne, CatchUpAll, CatchUpSkip:
+		return
+	}
+	return "", fmt.Errorf("unknown catch-up policy %q", c.CatchUp)
+}
+
+// missed returns the activations of `schedule` after `from` up to `to`,
+// which are at most the last maxMissed ones
+func missed(schedule Schedule, from, to time.Time) (activations []time.Time) {
+	for next := schedule.Next(from); !next.IsZero() && !next.After(to); next = schedule.Next(next) {
+		activations = append(activations, next)
+		if len(activations) > maxMissed {
+			activations = activations[1:]
+		}
+	}
+	return
+}
+
+// runSubscriptions calls `job` for every activation of the schedule of each
+// subscription in `database` until `stop` is closed and records when the
+// reminder of a subscription was sent successfully. Subscriptions with an
+// invalid schedule or strategy are skipped.
+//
+// The activations of a subscription which were missed since its last
+// reminder, e.g. because the service was not running, are caught up with
+// according to the catch-up policy of `c`.
+func (c Config) runSubscriptions(database *db.Database, clock Clock, stop <-chan struct{},
+	job func(db.Subscription, time.Time) error) error {
+	policy, err := c.catchUpPolicy()
+	if err != nil {
+		return err
+	}
+	run := func(subscription db.Subscription, activation time.Time) {
+		if err := job(subscription, activation); err != nil {
+			log.Printf("subscription %d: %v", subscription.Id, err)
+			return
+		}
+		if err := database.SubscriptionSent(subscription.Id, clock.Now()); err != nil {
+			log.Println(err)
+		}
+	}
+	// last point in time up to which the activations of a subscription
+	// have been handled
+	handled := make(map[int]time.Time)
+	for {
+		select {
+		case <-stop:
+			return nil
+		default:
+		}
+		now := clock.Now()
+		wake := now.Add(pollInterval)
+		subscriptions, err := database.GetSubscriptions()
+		if err != nil {
+			log.Println(err)
+		}
+		for _, subscription := range subscriptions {
+			schedule, err := ScheduleOf(subscription)
+			if err == nil {
+				_, err = NewSelector(subscription.Strategy, subscription.TopicId, subscription.BookId)
+			}
+			if err != nil {
+				log.Printf("skipping subscription %d: %v", subscription.Id, err)
+				continue
+			}
+			from, ok := handled[subscription.Id]
+			if !ok {
+				// new subscriptions start now, the ones which were sent
+				// before catch up with the activations missed since
+				from = now
+				handled[subscription.Id] = now
+				if !subscription.LastSent.IsZero() && policy != CatchUpSkip {
+					activations := missed(schedule, subscription.LastSent, now)
+					if policy == CatchUpOne && len(activations) > 1 {
+						activations = activations[len(activations)-1:]
+					}
+					for _, activation := range activations {
+						run(subscription, activation)
+					}
+				}
+			}
+			next := schedule.Next(from)
+			if next.IsZero() {
+				continue
+			}
+			if !next.After(now) {
+				run(subscription, next)
+				handled[subscription.Id] = now
+				next = schedule.Next(now)
+			}
+			if !next.IsZero() && next.Before(wake) {
+				wake = next
+			}
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-clock.After(wake.Sub(now)):
+		}
+	}
+}
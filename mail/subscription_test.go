@@ -0,0 +1,234 @@
+package quote
+
+import (
+	"errors"
+	db "quote/db"
+	"testing"
+	"time"
+)
+
+func TestSeedSubscriptions(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	config := Config{
+		Receiver: []string{"first@mail.com", "second@mail.com"},
+		Schedule: "weekly",
+		Strategy: TopicStrategy,
+		TopicId:  2,
+	}
+	// Act
+	err = seedSubscriptions(database, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// seeding again does not create further subscriptions
+	err = seedSubscriptions(database, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Assert
+	subscriptions, err := database.GetSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expectedLen := 2; len(subscriptions) != expectedLen {
+		t.Fatalf(lenError, expectedLen, len(subscriptions))
+	}
+	for i, subscription := range subscriptions {
+		expected := Config{
+			Receiver: config.Receiver[i : i+1],
+			Schedule: config.Schedule,
+			Count:    5,
+			Strategy: config.Strategy,
+			TopicId:  config.TopicId,
+		}
+		actual := Config{}.subscription(subscription)
+		if actual.Receiver[0] != expected.Receiver[0] ||
+			actual.Schedule != expected.Schedule ||
+			actual.Count != expected.Count ||
+			actual.Strategy != expected.Strategy ||
+			actual.TopicId != expected.TopicId {
+			t.Errorf(contentError, expected, actual)
+		}
+	}
+}
+
+func TestRunSubscriptions(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	schedules := map[string]string{
+		"daily@mail.com":    "0 8 * * *",
+		"frequent@mail.com": "0 */6 * * *",
+		"invalid@mail.com":  "every day",
+	}
+	for recipient, schedule := range schedules {
+		subscription := database.NewSubscription(recipient)
+		subscription.Schedule = schedule
+		if _, err = subscription.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock := &fakeClock{now: time.Date(2022, 1, 1, 0, 30, 0, 0, time.UTC)}
+	stop := make(chan struct{})
+	activations := make(map[string][]time.Time)
+	jobs := 0
+	// Act
+	err = Config{}.runSubscriptions(database, clock, stop, func(subscription db.Subscription, activation time.Time) error {
+		activations[subscription.Recipient] = append(activations[subscription.Recipient], activation)
+		jobs += 1
+		if jobs == 5 {
+			close(stop)
+		}
+		return nil
+	})
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]time.Time{
+		"daily@mail.com": {
+			time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC),
+		},
+		"frequent@mail.com": {
+			time.Date(2022, 1, 1, 6, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 1, 18, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	if len(activations) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(activations))
+	}
+	for recipient, expectedActivations := range expected {
+		actualActivations := activations[recipient]
+		if len(actualActivations) != len(expectedActivations) {
+			t.Fatalf(lenError, len(expectedActivations), len(actualActivations))
+		}
+		for i, activation := range actualActivations {
+			if !activation.Equal(expectedActivations[i]) {
+				t.Errorf(nextError, schedules[recipient], expectedActivations[i], activation)
+			}
+		}
+	}
+}
+
+func TestRunSubscriptionsCatchUp(t *testing.T) {
+	lastSent := time.Date(2022, 1, 1, 8, 0, 0, 0, time.UTC)
+	cases := map[string][]time.Time{
+		"": {
+			time.Date(2022, 1, 4, 8, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 5, 8, 0, 0, 0, time.UTC),
+		},
+		CatchUpAll: {
+			time.Date(2022, 1, 2, 8, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 3, 8, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 4, 8, 0, 0, 0, time.UTC),
+			time.Date(2022, 1, 5, 8, 0, 0, 0, time.UTC),
+		},
+		CatchUpSkip: {
+			time.Date(2022, 1, 5, 8, 0, 0, 0, time.UTC),
+		},
+	}
+	for policy, expected := range cases {
+		// Arrange
+		initDatabase(t)
+		database, err := db.Connect(testDatabase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subscription := database.NewSubscription("to@mail.com")
+		subscription.Schedule = "0 8 * * *"
+		subscription.TimeZone = "UTC"
+		id, err := subscription.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = database.SubscriptionSent(id, lastSent); err != nil {
+			t.Fatal(err)
+		}
+		clock := &fakeClock{now: time.Date(2022, 1, 4, 12, 0, 0, 0, time.UTC)}
+		stop := make(chan struct{})
+		var activations []time.Time
+		// Act
+		err = Config{CatchUp: policy}.runSubscriptions(database, clock, stop, func(subscription db.Subscription, activation time.Time) error {
+			activations = append(activations, activation)
+			if !activation.Before(expected[len(expected)-1]) {
+				close(stop)
+			}
+			return nil
+		})
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(activations) != len(expected) {
+			t.Fatalf(lenError, len(expected), len(activations))
+		}
+		for i, activation := range activations {
+			if !activation.Equal(expected[i]) {
+				t.Errorf(nextError, policy, expected[i], activation)
+			}
+		}
+		stored, err := database.GetSubscription(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sent := expected[len(expected)-1]; !stored.LastSent.Equal(sent) {
+			t.Errorf(contentError, sent, stored.LastSent)
+		}
+		database.Close()
+	}
+}
+
+func TestRunSubscriptionsFailedJob(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	id, err := database.NewSubscription("to@mail.com").Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{now: time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)}
+	stop := make(chan struct{})
+	// Act
+	err = Config{}.runSubscriptions(database, clock, stop, func(subscription db.Subscription, activation time.Time) error {
+		close(stop)
+		return errors.New("delivery failed")
+	})
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := database.GetSubscription(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.LastSent.IsZero() {
+		t.Errorf("Expected no successful reminder but got %v", stored.LastSent)
+	}
+}
+
+func TestUnknownCatchUpPolicy(t *testing.T) {
+	// Arrange
+	config := Config{CatchUp: "some"}
+	// Act
+	err := config.runSubscriptions(nil, &fakeClock{}, nil, nil)
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for an unknown catch-up policy but got nil")
+	}
+}
@@ -0,0 +1,231 @@
+package quote
+
+import (
+	"bytes"
+	"crypto/rand"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	db "quote/db"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+const defaultSubject = "Quote-reminder"
+
+// reminder is passed to the templates of a reminder mail
+type reminder struct {
+	Subject string
+	Date    time.Time
+	Quotes  []db.Quote
+	// Recipient of the reminder, empty if it is sent to several recipients
+	Recipient string
+	// Quiz is set if key words of the quotes are blanked out
+	Quiz   bool
+	clozes map[int]string
+	config Config
+}
+
+// Cloze returns the quiz version of `quote`, which is its text if quiz mode
+// is disabled
+func (r reminder) Cloze(quote db.Quote) string {
+	if cloze, ok := r.clozes[quote.Id]; ok {
+		return cloze
+	}
+	return quote.Quote
+}
+
+// Answer returns the link to `quote` in the REST api revealing its full text
+// in quiz mode, which is empty if quiz mode is disabled or FeedbackUrl is not
+// configured
+func (r reminder) Answer(quote db.Quote) string {
+	if !r.Quiz || r.config.FeedbackUrl == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/api/quotes/%d", strings.TrimRight(r.config.FeedbackUrl, "/"), quote.Id)
+}
+
+// Link returns the feedback link of `action` for `quote`, which is empty if
+// feedback links are not configured
+func (r reminder) Link(action string, quote db.Quote) string {
+	if r.Recipient == "" {
+		return ""
+	}
+	return r.config.FeedbackLink(action, r.Recipient, quote.Id)
+}
+
+// Unsubscribe returns the link to unsubscribe from the reminders, which is
+// empty if feedback links are not configured
+func (r reminder) Unsubscribe() string {
+	if r.Recipient == "" {
+		return ""
+	}
+	return r.config.FeedbackLink(UnsubscribeAction, r.Recipient, 0)
+}
+
+// textTemplate parses the plain text template configured in `path` or the
+// embedded default if `path` is empty
+func textTemplate(path, name string) (*texttemplate.Template, error) {
+	if path == "" {
+		return texttemplate.ParseFS(defaultTemplates, "templates/"+name)
+	}
+	return texttemplate.ParseFiles(path)
+}
+
+// htmlTemplate parses the html template configured in `path` or the embedded
+// default if `path` is empty
+func htmlTemplate(path, name string) (*htmltemplate.Template, error) {
+	if path == "" {
+		return htmltemplate.ParseFS(defaultTemplates, "templates/"+name)
+	}
+	return htmltemplate.ParseFiles(path)
+}
+
+// messageId creates a unique Message-ID for the domain of `sender`
+func messageId(sender *mail.Address) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(sender.Address, "@"); i >= 0 {
+		domain = sender.Address[i+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(random), domain), nil
+}
+
+// header writes the header fields of a mail from `sender` to `receivers`
+func header(w io.Writer, sender *mail.Address, receivers []*mail.Address,
+	subject string, date time.Time) error {
+	id, err := messageId(sender)
+	if err != nil {
+		return err
+	}
+	var to []string
+	for _, receiver := range receivers {
+		to = append(to, receiver.String())
+	}
+	fmt.Fprintf(w, "From: %s\r\n", sender.String())
+	fmt.Fprintf(w, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(w, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(w, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(w, "Message-ID: %s\r\n", id)
+	fmt.Fprintf(w, "MIME-Version: 1.0\r\n")
+	return nil
+}
+
+// alternative writes a multipart/alternative body with a plain text and a
+// html part to `w`, both quoted-printable encoded. The Content-Type header
+// (terminating the header section) is written as well.
+func alternative(w io.Writer, text, html []byte) (err error) {
+	body := multipart.NewWriter(w)
+	fmt.Fprintf(w, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", body.Boundary())
+	return alternativeParts(body, text, html)
+}
+
+// alternativeParts writes the parts of a multipart/alternative body to `body`
+// and closes it
+func alternativeParts(body *multipart.Writer, text, html []byte) (err error) {
+	parts := []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, part := range parts {
+		var partWriter io.Writer
+		partWriter, err = body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err = encoder.Write(part.content); err != nil {
+			return
+		}
+		if err = encoder.Close(); err != nil {
+			return
+		}
+	}
+	return body.Close()
+}
+
+// attachment is a file attached to a mail
+type attachment struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+// mixed writes a multipart/mixed body to `w`, which contains the
+// multipart/alternative body of `text` and `html` (see alternative) followed
+// by the base64 encoded `attachments`. The Content-Type header is written as
+// well.
+func mixed(w io.Writer, text, html []byte, attachments []attachment) (err error) {
+	body := multipart.NewWriter(w)
+	fmt.Fprintf(w, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", body.Boundary())
+	var buffer bytes.Buffer
+	inner := multipart.NewWriter(&buffer)
+	if err = alternativeParts(inner, text, html); err != nil {
+		return
+	}
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + inner.Boundary()},
+	})
+	if err != nil {
+		return
+	}
+	if _, err = part.Write(buffer.Bytes()); err != nil {
+		return
+	}
+	for _, file := range attachments {
+		part, err = body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(file.contentType, map[string]string{"name": file.name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": file.name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return
+		}
+		// base64 lines are limited to 76 characters
+		encoded := base64.StdEncoding.EncodeToString(file.content)
+		for len(encoded) > 0 {
+			n := 76
+			if len(encoded) < n {
+				n = len(encoded)
+			}
+			if _, err = io.WriteString(part, encoded[:n]+"\r\n"); err != nil {
+				return
+			}
+			encoded = encoded[n:]
+		}
+	}
+	return body.Close()
+}
+
+// render executes the text and html templates for `data`
+func render(text *texttemplate.Template, html *htmltemplate.Template, data interface{}) (textBody, htmlBody []byte, err error) {
+	var textBuffer, htmlBuffer bytes.Buffer
+	if err = text.Execute(&textBuffer, data); err != nil {
+		return
+	}
+	if err = html.Execute(&htmlBuffer, data); err != nil {
+		return
+	}
+	return textBuffer.Bytes(), htmlBuffer.Bytes(), nil
+}
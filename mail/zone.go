@@ -0,0 +1,133 @@
+package quote
+
+import (
+	"errors"
+	"fmt"
+	db "quote/db"
+	"strings"
+	"time"
+)
+
+// parseClock parses a time of day (HH:MM) into the minutes since midnight
+func parseClock(value string) (minutes int, err error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// withSendTime returns the cron expression of `spec` with its minute and hour
+// replaced by the time of day `sendTime`
+func withSendTime(spec, sendTime string) (string, error) {
+	minutes, err := parseClock(sendTime)
+	if err != nil {
+		return "", err
+	}
+	if preset, ok := presets[strings.ToLower(spec)]; ok {
+		spec = preset
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return "", fmt.Errorf("schedule %q: expected 5 fields but got %d", spec, len(fields))
+	}
+	fields[0] = fmt.Sprint(minutes % 60)
+	fields[1] = fmt.Sprint(minutes / 60)
+	return strings.Join(fields, " "), nil
+}
+
+// zonedSchedule evaluates a Schedule on the wall clock of Location.
+type zonedSchedule struct {
+	Schedule Schedule
+	Location *time.Location
+}
+
+func (s zonedSchedule) Next(t time.Time) time.Time {
+	return s.Schedule.Next(t.In(s.Location))
+}
+
+// quietSchedule postpones the activations of a Schedule within the quiet hours
+// from start up to end (in minutes since midnight) of Location to their end.
+// The quiet hours span midnight if start is after end.
+type quietSchedule struct {
+	Schedule   Schedule
+	Location   *time.Location
+	start, end int
+}
+
+// quiet returns the quiet hours containing `t`, ok is false if `t` is not
+// within the quiet hours
+func (s quietSchedule) quiet(t time.Time) (start, end time.Time, ok bool) {
+	t = t.In(s.Location)
+	minutes := t.Hour()*60 + t.Minute()
+	startDay, endDay := t.Day(), t.Day()
+	switch {
+	case s.start < s.end:
+		ok = minutes >= s.start && minutes < s.end
+	case minutes >= s.start:
+		ok, endDay = true, endDay+1
+	case minutes < s.end:
+		ok, startDay = true, startDay-1
+	}
+	if !ok {
+		return
+	}
+	wall := func(day, minutes int) time.Time {
+		return localTime(time.Date(t.Year(), t.Month(), day, minutes/60, minutes%60, 0, 0, time.UTC), s.Location)
+	}
+	return wall(startDay, s.start), wall(endDay, s.end), true
+}
+
+func (s quietSchedule) Next(t time.Time) time.Time {
+	// an activation within the current quiet hours before `t` is still due
+	// at their end, e.g. if the service was started in the quiet hours
+	if start, end, ok := s.quiet(t); ok {
+		if previous := s.Schedule.Next(start.Add(-time.Second)); !previous.IsZero() && !previous.After(t) {
+			return end
+		}
+	}
+	next := s.Schedule.Next(t)
+	if next.IsZero() {
+		return next
+	}
+	if _, end, ok := s.quiet(next); ok {
+		return end
+	}
+	return next
+}
+
+// ScheduleOf returns the Schedule of `subscription`, which is evaluated in
+// its time zone, at its send time and outside of its quiet hours
+func ScheduleOf(subscription db.Subscription) (schedule Schedule, err error) {
+	spec := subscription.Schedule
+	if subscription.SendTime != "" {
+		if spec, err = withSendTime(spec, subscription.SendTime); err != nil {
+			return
+		}
+	}
+	if schedule, err = ParseSchedule(spec); err != nil {
+		return
+	}
+	location := time.Local
+	if subscription.TimeZone != "" {
+		if location, err = time.LoadLocation(subscription.TimeZone); err != nil {
+			return
+		}
+	}
+	schedule = zonedSchedule{Schedule: schedule, Location: location}
+	if subscription.QuietStart == "" && subscription.QuietEnd == "" {
+		return
+	}
+	start, err := parseClock(subscription.QuietStart)
+	if err != nil {
+		return
+	}
+	end, err := parseClock(subscription.QuietEnd)
+	if err != nil {
+		return
+	}
+	if start == end {
+		return nil, errors.New("quiet hours must not be empty")
+	}
+	return quietSchedule{Schedule: schedule, Location: location, start: start, end: end}, nil
+}
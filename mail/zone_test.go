@@ -0,0 +1,212 @@
+package quote
+
+import (
+	db "quote/db"
+	"testing"
+	"time"
+)
+
+func location(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestScheduleNextAcrossDaylightSavingTime(t *testing.T) {
+	// Arrange
+	berlin := location(t, "Europe/Berlin")
+	newYork := location(t, "America/New_York")
+	cases := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		// the local time of a daily reminder stays the same
+		{"0 8 * * *", time.Date(2022, 3, 26, 9, 0, 0, 0, berlin),
+			time.Date(2022, 3, 27, 6, 0, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2022, 10, 29, 9, 0, 0, 0, berlin),
+			time.Date(2022, 10, 30, 7, 0, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2022, 3, 12, 9, 0, 0, 0, newYork),
+			time.Date(2022, 3, 13, 12, 0, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2022, 11, 5, 9, 0, 0, 0, newYork),
+			time.Date(2022, 11, 6, 13, 0, 0, 0, time.UTC)},
+		// skipped times are moved forward by the gap
+		{"30 2 * * *", time.Date(2022, 3, 26, 12, 0, 0, 0, berlin),
+			time.Date(2022, 3, 27, 3, 30, 0, 0, berlin)},
+		{"30 2 * * *", time.Date(2022, 3, 12, 12, 0, 0, 0, newYork),
+			time.Date(2022, 3, 13, 3, 30, 0, 0, newYork)},
+		// repeated times are activated once, at their first occurrence
+		{"30 2 * * *", time.Date(2022, 10, 29, 12, 0, 0, 0, berlin),
+			time.Date(2022, 10, 30, 0, 30, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2022, 10, 30, 0, 30, 0, 0, time.UTC).In(berlin),
+			time.Date(2022, 10, 31, 1, 30, 0, 0, time.UTC)},
+		{"30 1 * * *", time.Date(2022, 11, 6, 5, 30, 0, 0, time.UTC).In(newYork),
+			time.Date(2022, 11, 7, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Act
+		actual := schedule.Next(c.from)
+		// Assert
+		if !actual.Equal(c.expected) {
+			t.Errorf(nextError, c.spec, c.expected, actual)
+		}
+	}
+}
+
+func TestScheduleOf(t *testing.T) {
+	// Arrange
+	location(t, "Europe/Berlin")
+	from := time.Date(2022, 1, 5, 10, 15, 0, 0, time.UTC)
+	cases := []struct {
+		subscription db.Subscription
+		expected     time.Time
+	}{
+		{db.Subscription{Schedule: "daily", TimeZone: "Europe/Berlin"},
+			time.Date(2022, 1, 5, 23, 0, 0, 0, time.UTC)},
+		{db.Subscription{Schedule: "daily", TimeZone: "Europe/Berlin", SendTime: "07:45"},
+			time.Date(2022, 1, 6, 6, 45, 0, 0, time.UTC)},
+		{db.Subscription{Schedule: "0 * * * *", TimeZone: "UTC", QuietStart: "11:00", QuietEnd: "13:30"},
+			time.Date(2022, 1, 5, 13, 30, 0, 0, time.UTC)},
+		// quiet hours spanning midnight
+		{db.Subscription{Schedule: "0 23 * * *", TimeZone: "UTC", QuietStart: "22:00", QuietEnd: "06:00"},
+			time.Date(2022, 1, 6, 6, 0, 0, 0, time.UTC)},
+		{db.Subscription{Schedule: "0 2 * * *", TimeZone: "UTC", QuietStart: "22:00", QuietEnd: "06:00"},
+			time.Date(2022, 1, 6, 6, 0, 0, 0, time.UTC)},
+		{db.Subscription{Schedule: "0 12 * * *", TimeZone: "UTC", QuietStart: "22:00", QuietEnd: "06:00"},
+			time.Date(2022, 1, 5, 12, 0, 0, 0, time.UTC)}, // an activation before the start within the quiet hours is still due
+		{db.Subscription{Schedule: "0 10 * * *", TimeZone: "UTC", QuietStart: "09:00", QuietEnd: "11:00"},
+			time.Date(2022, 1, 5, 11, 0, 0, 0, time.UTC)},
+		{db.Subscription{Schedule: "0 9 * * *", TimeZone: "UTC", QuietStart: "10:00", QuietEnd: "11:00"},
+			time.Date(2022, 1, 6, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := ScheduleOf(c.subscription)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Act
+		actual := schedule.Next(from)
+		// Assert
+		if !actual.Equal(c.expected) {
+			t.Errorf(nextError, c.subscription.Schedule, c.expected, actual)
+		}
+	}
+}
+
+func TestInvalidScheduleOf(t *testing.T) {
+	// Arrange
+	subscriptions := []db.Subscription{
+		{Schedule: "daily", TimeZone: "Mars/Olympus_Mons"},
+		{Schedule: "daily", SendTime: "25:00"},
+		{Schedule: "daily", SendTime: "morning"},
+		{Schedule: "daily", QuietStart: "22:00"},
+		{Schedule: "daily", QuietStart: "22:00", QuietEnd: "22:00"},
+		{Schedule: "yearly", SendTime: "08:00"},
+	}
+	for _, subscription := range subscriptions {
+		// Act
+		_, err := ScheduleOf(subscription)
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %v but got nil", subscription)
+		}
+	}
+}
+
+func TestRunSubscriptionsAcrossDaylightSavingTime(t *testing.T) {
+	// Arrange
+	location(t, "America/New_York")
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	subscription := database.NewSubscription("to@mail.com")
+	subscription.TimeZone = "America/New_York"
+	subscription.SendTime = "07:30"
+	if _, err = subscription.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{now: time.Date(2022, 3, 12, 0, 0, 0, 0, time.UTC)}
+	stop := make(chan struct{})
+	var activations []time.Time
+	// Act
+	err = Config{}.runSubscriptions(database, clock, stop, func(subscription db.Subscription, activation time.Time) error {
+		activations = append(activations, activation)
+		if len(activations) == 3 {
+			close(stop)
+		}
+		return nil
+	})
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{
+		time.Date(2022, 3, 12, 12, 30, 0, 0, time.UTC),
+		time.Date(2022, 3, 13, 11, 30, 0, 0, time.UTC),
+		time.Date(2022, 3, 14, 11, 30, 0, 0, time.UTC),
+	}
+	if len(activations) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(activations))
+	}
+	for i, activation := range activations {
+		if !activation.Equal(expected[i]) {
+			t.Errorf(nextError, "07:30 America/New_York", expected[i], activation)
+		}
+	}
+}
+
+func TestRunSubscriptionsStartedInQuietHours(t *testing.T) {
+	// Arrange
+	initDatabase(t)
+	database, err := db.Connect(testDatabase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	subscription := database.NewSubscription("to@mail.com")
+	subscription.Schedule = "0 23 * * *"
+	subscription.TimeZone = "UTC"
+	subscription.QuietStart = "22:00"
+	subscription.QuietEnd = "06:00"
+	if _, err = subscription.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// the service starts after the activation at 23:00, which is postponed
+	// to the end of the quiet hours
+	clock := &fakeClock{now: time.Date(2022, 1, 6, 2, 0, 0, 0, time.UTC)}
+	stop := make(chan struct{})
+	var activations []time.Time
+	// Act
+	err = Config{}.runSubscriptions(database, clock, stop, func(subscription db.Subscription, activation time.Time) error {
+		activations = append(activations, activation)
+		if len(activations) == 2 {
+			close(stop)
+		}
+		return nil
+	})
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{
+		time.Date(2022, 1, 6, 6, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 7, 6, 0, 0, 0, time.UTC),
+	}
+	if len(activations) != len(expected) {
+		t.Fatalf(lenError, len(expected), len(activations))
+	}
+	for i, activation := range activations {
+		if !activation.Equal(expected[i]) {
+			t.Errorf(nextError, subscription.Schedule, expected[i], activation)
+		}
+	}
+}
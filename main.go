@@ -1,14 +1,22 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
 	api "quote/api"
 	db "quote/db"
+	inbound "quote/inbound"
 	mail "quote/mail"
+	secret "quote/secret"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -22,9 +30,11 @@ type ServerConfig struct {
 	Address string
 	Port    int
 	Timeout time.Duration
+	// DeletePolicy is either "restrict" or "cascade"
+	DeletePolicy string
 }
 
-func ApiService(database *db.Database) {
+func ApiService(database *db.Database, config mail.Config) {
 	// read api server configuration
 	configJson, err := ioutil.ReadFile(serverConfigFilename)
 	if err != nil {
@@ -35,9 +45,13 @@ func ApiService(database *db.Database) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	deletePolicy, err := db.ParseDeletePolicy(serverConfig.DeletePolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
 	// start api service
 	server := &http.Server{
-		Handler: api.GetRouter(database),
+		Handler: api.GetRouter(database, config, deletePolicy),
 		Addr: fmt.Sprintf("%s:%d",
 			serverConfig.Address, serverConfig.Port),
 		WriteTimeout: serverConfig.Timeout,
@@ -46,22 +60,161 @@ func ApiService(database *db.Database) {
 	log.Fatal(server.ListenAndServe())
 }
 
-func MailService(database *db.Database) {
+func readMailConfig() (config mail.Config) {
 	// read mail service configuration
 	configJson, err := ioutil.ReadFile(configFilename)
 	if err != nil {
 		log.Fatal(err)
 	}
-	config := mail.Config{}
 	err = json.Unmarshal(configJson, &config)
 	if err != nil {
 		log.Fatal(err)
 	}
-	// start mail service
-	mail.Service(database, config)
+	return
+}
+
+func MailConfig(secrets ...*secret.Secret) (config mail.Config) {
+	// read mail service configuration with its secrets and the `secrets`
+	// of other services, which are loaded from the same keystore
+	config = readMailConfig()
+	err := config.LoadSecrets(secrets...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+func InboundConfig() (config inbound.Config) {
+	// read the configuration of the inbound mail listener, which is part of
+	// the mail service configuration
+	configJson, err := ioutil.ReadFile(configFilename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var wrapper struct{ Inbound inbound.Config }
+	err = json.Unmarshal(configJson, &wrapper)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return wrapper.Inbound
+}
+
+func InboundService(database *db.Database, config inbound.Config, mailConfig mail.Config) {
+	server := inbound.Server{
+		Config:   config,
+		Database: database,
+		Reply:    mailConfig.Reply,
+	}
+	log.Fatal(server.ListenAndServe())
+}
+
+// KeystoreCommand lists, sets or deletes the secrets of the keystore of the
+// mail service configuration, the keystore is created if it does not exist
+func KeystoreCommand(args []string) error {
+	config := readMailConfig()
+	if config.Keystore == "" {
+		return errors.New("no keystore is configured")
+	}
+	passphrase, err := config.Passphrase()
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return fmt.Errorf("no keystore passphrase is configured, set %s", mail.KeystorePassphraseEnv)
+	}
+	keystore, err := secret.OpenKeystore(config.Keystore, passphrase)
+	if os.IsNotExist(err) {
+		keystore, err = secret.Keystore{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		var names []string
+		for name := range keystore {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	case len(args) == 2 && args[0] == "set":
+		// the secret is read from the standard input, so that it does not
+		// end up in the shell history
+		fmt.Fprintf(os.Stderr, "Value of %s: ", args[1])
+		value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		keystore[args[1]] = strings.TrimRight(value, "\r\n")
+	case len(args) == 2 && args[0] == "delete":
+		if _, ok := keystore[args[1]]; !ok {
+			return fmt.Errorf("secret %s is not in the keystore", args[1])
+		}
+		delete(keystore, args[1])
+	default:
+		return errors.New("usage: keystore list | keystore set NAME | keystore delete NAME")
+	}
+	return keystore.Save(config.Keystore, passphrase)
+}
+
+// MigrateCommand shows the migration status of the database or migrates it to
+// the latest schema version
+func MigrateCommand(args []string) error {
+	database, err := db.Open(dbFilename)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	switch {
+	case len(args) == 1 && args[0] == "status":
+		status, err := database.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, migration := range status {
+			state := "pending"
+			if migration.Applied {
+				state = "applied " + migration.AppliedDate.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d %-30s %s\n", migration.Version, migration.Name, state)
+		}
+		return nil
+	case len(args) == 0 || len(args) == 1 && args[0] == "up":
+		applied, err := database.Migrate()
+		for _, migration := range applied {
+			fmt.Printf("applied %04d %s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		version, err := database.SchemaVersion()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("schema version %d\n", version)
+		return nil
+	default:
+		return errors.New("usage: migrate [up] | migrate status")
+	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "keystore" {
+		if err := KeystoreCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := MigrateCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// connect/create to local database
 	database, err := db.Connect(dbFilename)
 	if err != nil {
@@ -70,8 +223,13 @@ func main() {
 	defer database.Close()
 
 	// start services concurrently
-	go MailService(database)
-	go ApiService(database)
+	inboundConfig := InboundConfig()
+	config := MailConfig(&inboundConfig.Token)
+	go mail.Service(database, config)
+	go ApiService(database, config)
+	if inboundConfig.Address != "" {
+		go InboundService(database, inboundConfig, config)
+	}
 
 	fmt.Println("Services are running... Press enter to cancel...")
 	fmt.Scanln()
@@ -0,0 +1,129 @@
+package quote
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+)
+
+// keyLength is the length of the AES-256 key derived from the passphrase
+const keyLength = 32
+
+// saltLength is the length of the random salt of the key derivation
+const saltLength = 16
+
+// iterations of PBKDF2 used for new keystores, existing ones keep the number
+// they were created with
+var iterations = 600000
+
+// Keystore holds named secrets, which are stored in a file encrypted with a
+// passphrase
+type Keystore map[string]string
+
+// keystoreFile is the JSON content of a keystore file. The entries are
+// encrypted with AES-256-GCM using a key derived from the passphrase with
+// PBKDF2-HMAC-SHA256.
+type keystoreFile struct {
+	Iterations int
+	Salt       []byte
+	Nonce      []byte
+	Data       []byte
+}
+
+// pbkdf2 derives a key of `length` bytes from `password` and `salt` with
+// PBKDF2 (RFC 8018) using HMAC-SHA256 as pseudorandom function
+func pbkdf2(password, salt []byte, iterations, length int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < length; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(block >> 24), byte(block >> 16), byte(block >> 8), byte(block)})
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:length]
+}
+
+// gcm returns the AES-GCM cipher of the key derived from `passphrase`
+func gcm(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2([]byte(passphrase), salt, iterations, keyLength))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// OpenKeystore decrypts the keystore file at `path` with `passphrase`
+func OpenKeystore(path, passphrase string) (Keystore, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keystoreFile
+	if err = json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	if file.Iterations < 1 {
+		return nil, errors.New("invalid keystore")
+	}
+	aead, err := gcm(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid keystore")
+	}
+	data, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, errors.New("wrong passphrase or corrupted keystore")
+	}
+	keystore := make(Keystore)
+	err = json.Unmarshal(data, &keystore)
+	return keystore, err
+}
+
+// Save encrypts the keystore with `passphrase` and writes it to the file at
+// `path`, which is only readable by its owner. A new salt and nonce are used
+// for each save.
+func (k Keystore) Save(path, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("the keystore needs a passphrase")
+	}
+	data, err := json.Marshal(k)
+	if err != nil {
+		return err
+	}
+	file := keystoreFile{Iterations: iterations, Salt: make([]byte, saltLength)}
+	if _, err = rand.Read(file.Salt); err != nil {
+		return err
+	}
+	aead, err := gcm(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, data, nil)
+	content, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
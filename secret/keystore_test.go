@@ -0,0 +1,93 @@
+package quote
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+)
+
+const contentError = "content had not the expected value\nexpected: %v\nactual: %v\n"
+
+func TestPbkdf2(t *testing.T) {
+	// Arrange
+	// test vectors of PBKDF2-HMAC-SHA256
+	cases := []struct {
+		password, salt string
+		iterations     int
+		length         int
+		expected       string
+	}{
+		{"password", "salt", 1, 32, "120fb6cffcf8b32c43e7225256c4f837a86548c92ccc35480805987cb70be17b"},
+		{"password", "salt", 2, 32, "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"},
+		{"password", "salt", 4096, 32, "c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a"},
+		// the key spans two blocks
+		{"passwordPASSWORDpassword", "saltSALTsaltSALTsaltSALTsaltSALTsalt", 4096, 40,
+			"348c89dbcbd32b2f32d814b8116e84cf2b17347ebc1800181c4e2a1fb8dd53e1c635518c7dac47e9"},
+	}
+	for _, c := range cases {
+		// Act
+		actual := hex.EncodeToString(pbkdf2([]byte(c.password), []byte(c.salt), c.iterations, c.length))
+		// Assert
+		if actual != c.expected {
+			t.Errorf(contentError, c.expected, actual)
+		}
+	}
+}
+
+// fastKeystores lowers the iterations of the key derivation for the test
+func fastKeystores(t *testing.T) {
+	previous := iterations
+	iterations = 1000
+	t.Cleanup(func() { iterations = previous })
+}
+
+func TestKeystore(t *testing.T) {
+	// Arrange
+	fastKeystores(t)
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	keystore := Keystore{"smtp": "topsecret", "feedback": "change-me"}
+	// Act
+	err := keystore.Save(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := OpenKeystore(path, "passphrase")
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(keystore) {
+		t.Fatalf(contentError, keystore, actual)
+	}
+	for name, value := range keystore {
+		if actual[name] != value {
+			t.Errorf(contentError, value, actual[name])
+		}
+	}
+}
+
+func TestKeystoreWrongPassphrase(t *testing.T) {
+	// Arrange
+	fastKeystores(t)
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := (Keystore{"smtp": "topsecret"}).Save(path, "passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	// Act
+	_, err := OpenKeystore(path, "wrong")
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for a wrong passphrase but got nil")
+	}
+}
+
+func TestKeystoreWithoutPassphrase(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	// Act
+	err := Keystore{"smtp": "topsecret"}.Save(path, "")
+	// Assert
+	if err == nil {
+		t.Error("Expected an error for an empty passphrase but got nil")
+	}
+}
@@ -0,0 +1,135 @@
+package quote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Redacted replaces the value of a secret when it is printed or marshalled
+const Redacted = "[redacted]"
+
+// Secret is a confidential setting like a password, which is never revealed
+// when it is printed or marshalled to JSON. In a JSON configuration a secret
+// is either its value or an object naming the source it is loaded from (see
+// Resolve):
+//
+//	"password": "topsecret"
+//	"password": {"env": "QUOTE_PASSWORD"}
+//	"password": {"file": "/run/credentials/quote/password"}
+//	"password": {"keystore": "smtp"}
+type Secret struct {
+	value string
+	// env, file and keystore are the sources of the value, at most one of
+	// them is set
+	env      string
+	file     string
+	keystore string
+}
+
+// New creates a secret with the value `value`
+func New(value string) Secret {
+	return Secret{value: value}
+}
+
+// Value returns the value of the secret, which is empty until it is resolved
+// if it is loaded from a source
+func (s Secret) Value() string {
+	return s.value
+}
+
+// IsZero reports whether the secret has neither a value nor a source
+func (s Secret) IsZero() bool {
+	return s == Secret{}
+}
+
+// String returns Redacted, or an empty string if the secret is not set
+func (s Secret) String() string {
+	if s.IsZero() {
+		return ""
+	}
+	return Redacted
+}
+
+// GoString redacts the secret like String for the %#v verb
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalJSON redacts the secret like String
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON reads the value of the secret or its source
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var source struct {
+			Env      string
+			File     string
+			Keystore string
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&source); err != nil {
+			return fmt.Errorf("invalid secret: %v", err)
+		}
+		sources := 0
+		for _, name := range []string{source.Env, source.File, source.Keystore} {
+			if name != "" {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return errors.New("a secret needs exactly one of env, file or keystore")
+		}
+		*s = Secret{env: source.Env, file: source.File, keystore: source.Keystore}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = New(value)
+	return nil
+}
+
+// Resolve loads the value of the secret from its source, which is the
+// environment variable, the file or the entry of `keystore`. Trailing line
+// breaks of a file are removed. Secrets which are given by their value are
+// left unchanged.
+func (s *Secret) Resolve(keystore Keystore) error {
+	switch {
+	case s.env != "":
+		value, ok := os.LookupEnv(s.env)
+		if !ok {
+			return fmt.Errorf("environment variable %s of secret is not set", s.env)
+		}
+		s.value = value
+	case s.file != "":
+		content, err := ioutil.ReadFile(s.file)
+		if err != nil {
+			return err
+		}
+		s.value = strings.TrimRight(string(content), "\r\n")
+	case s.keystore != "":
+		if keystore == nil {
+			return fmt.Errorf("secret %s needs a keystore", s.keystore)
+		}
+		value, ok := keystore[s.keystore]
+		if !ok {
+			return fmt.Errorf("secret %s is not in the keystore", s.keystore)
+		}
+		s.value = value
+	}
+	return nil
+}
+
+// InKeystore reports whether the secret is loaded from a keystore
+func (s Secret) InKeystore() bool {
+	return s.keystore != ""
+}
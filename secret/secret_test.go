@@ -0,0 +1,138 @@
+package quote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type config struct {
+	Password Secret
+}
+
+func TestUnmarshalSecret(t *testing.T) {
+	// Arrange
+	cases := []struct {
+		json     string
+		expected Secret
+	}{
+		{`{"password": "topsecret"}`, New("topsecret")},
+		{`{"password": {"env": "QUOTE_PASSWORD"}}`, Secret{env: "QUOTE_PASSWORD"}},
+		{`{"password": {"file": "/run/password"}}`, Secret{file: "/run/password"}},
+		{`{"password": {"keystore": "smtp"}}`, Secret{keystore: "smtp"}},
+		{`{}`, Secret{}},
+	}
+	for _, c := range cases {
+		var actual config
+		// Act
+		err := json.Unmarshal([]byte(c.json), &actual)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual.Password != c.expected {
+			t.Errorf(contentError, c.expected, actual.Password)
+		}
+	}
+}
+
+func TestUnmarshalInvalidSecret(t *testing.T) {
+	// Arrange
+	cases := []string{
+		`{"password": {}}`,
+		`{"password": {"env": "QUOTE_PASSWORD", "file": "/run/password"}}`,
+		`{"password": {"vault": "smtp"}}`,
+		`{"password": 42}`,
+	}
+	for _, c := range cases {
+		var actual config
+		// Act
+		err := json.Unmarshal([]byte(c), &actual)
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %s but got nil", c)
+		}
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	// Arrange
+	settings := config{Password: New("topsecret")}
+	// Act
+	printed := []string{fmt.Sprint(settings), fmt.Sprintf("%+v", settings),
+		fmt.Sprintf("%#v", settings), fmt.Sprintf("%s", settings.Password)}
+	marshalled, err := json.Marshal(settings)
+	// Assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range append(printed, string(marshalled)) {
+		if strings.Contains(text, "topsecret") || !strings.Contains(text, Redacted) {
+			t.Errorf(contentError, Redacted, text)
+		}
+	}
+	if expected, actual := `{"Password":""}`, mustMarshal(t, config{}); actual != expected {
+		t.Errorf(contentError, expected, actual)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestResolveSecret(t *testing.T) {
+	// Arrange
+	t.Setenv("QUOTE_TEST_PASSWORD", "from-env")
+	file := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keystore := Keystore{"smtp": "from-keystore"}
+	cases := []struct {
+		secret   Secret
+		expected string
+	}{
+		{New("plain"), "plain"},
+		{Secret{env: "QUOTE_TEST_PASSWORD"}, "from-env"},
+		{Secret{file: file}, "from-file"},
+		{Secret{keystore: "smtp"}, "from-keystore"},
+	}
+	for _, c := range cases {
+		// Act
+		err := c.secret.Resolve(keystore)
+		// Assert
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := c.secret.Value(); actual != c.expected {
+			t.Errorf(contentError, c.expected, actual)
+		}
+	}
+}
+
+func TestResolveMissingSecret(t *testing.T) {
+	// Arrange
+	secrets := []Secret{
+		{env: "QUOTE_TEST_UNSET"},
+		{file: filepath.Join(t.TempDir(), "missing")},
+		{keystore: "missing"},
+	}
+	for _, secret := range secrets {
+		// Act
+		err := secret.Resolve(Keystore{})
+		// Assert
+		if err == nil {
+			t.Errorf("Expected an error for %#v but got nil", secret)
+		}
+	}
+	if err := (&Secret{keystore: "smtp"}).Resolve(nil); err == nil {
+		t.Error("Expected an error without keystore but got nil")
+	}
+}